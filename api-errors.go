@@ -0,0 +1,53 @@
+package api
+
+import (
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+// How the API errors of every REST call map to SDK errors, see `apiContracts.ParseAPIError`.
+
+var loginPasswordAPIErrors = []apiContracts.APIErrorMapping{
+	{Code: apiContracts.API_ERROR_CODE_USER_OR_PASSWORD_INVALID, Error: apiContracts.ErrAPI_Auth_PasswordInvalid},
+	{Contains: apiContracts.API_ERROR_CODE_REASON_USER_OR_PASSWORD_INVALID, Error: apiContracts.ErrAPI_Auth_PasswordInvalid},
+	{Contains: apiContracts.API_ERROR_CODE_REASON_MISSING_USER, Error: apiContracts.ErrAPI_Auth_NoSuchUser},
+}
+
+var loginAuthHashAPIErrors = []apiContracts.APIErrorMapping{
+	{Code: apiContracts.API_ERROR_CODE_USER_OR_PASSWORD_INVALID, Error: apiContracts.ErrAPI_Auth_AuthHashInvalid},
+	{Contains: apiContracts.API_ERROR_CODE_REASON_USER_OR_PASSWORD_INVALID, Error: apiContracts.ErrAPI_Auth_AuthHashInvalid},
+	{Contains: apiContracts.API_ERROR_CODE_REASON_MISSING_USER, Error: apiContracts.ErrAPI_Auth_NoSuchUser},
+}
+
+var deviceUnregisterAPIErrors = []apiContracts.APIErrorMapping{
+	{Code: apiContracts.API_ERROR_CODE_SERVICE_NOT_FOUND_FOR_UID, Error: apiContracts.ErrAPI_Device_NoServiceFound},
+}
+
+var deviceTransferAPIErrors = []apiContracts.APIErrorMapping{}
+
+var deviceListAPIErrors = []apiContracts.APIErrorMapping{}
+
+var proxyCreateAPIErrors = []apiContracts.APIErrorMapping{
+	{Code: apiContracts.API_ERROR_CODE_SERVICE_NOT_FOUND_FOR_UID, Error: apiContracts.ErrAPI_ProxyCreate_NoServiceFound},
+}
+
+var proxyDeleteAPIErrors = []apiContracts.APIErrorMapping{}
+
+var serviceCreateAPIErrors = []apiContracts.APIErrorMapping{
+	{Contains: apiContracts.API_ERROR_CODE_REASON_MISSING_API_TOKEN, Error: apiContracts.ErrAPI_Helpers_NoToken},
+}
+
+var serviceRemoveAPIErrors = []apiContracts.APIErrorMapping{
+	{Code: apiContracts.API_ERROR_CODE_SERVICE_NOT_FOUND_FOR_UID, Error: apiContracts.ErrAPI_Service_NoServiceFound},
+}
+
+var serviceGenerateUIDAPIErrors = []apiContracts.APIErrorMapping{}
+
+var serviceRegisterAPIErrors = []apiContracts.APIErrorMapping{
+	{Code: apiContracts.API_ERROR_CODE_DEVICE_NOT_FOUND, Error: apiContracts.ErrAPI_Helpers_ServiceUIDNotFound},
+	{Contains: apiContracts.API_ERROR_CODE_REASON_BAD_DEVICE_ADDRESS, Error: apiContracts.ErrAPI_Helpers_ServiceUIDMissing},
+	{Contains: apiContracts.API_ERROR_CODE_REASON_MISSING_API_TOKEN, Error: apiContracts.ErrAPI_Helpers_NoToken},
+}
+
+var autoRegistrationAPIErrors = []apiContracts.APIErrorMapping{
+	{Contains: apiContracts.API_ERROR_CODE_REASON_NO_MATCHING_BULK_PROJECT, Error: apiContracts.ErrAutoreg_NoMatchingRegInfo},
+}
@@ -0,0 +1,160 @@
+package apitest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+func (thisRef *Server) serveBulk(w http.ResponseWriter, r *http.Request, path string) {
+	switch {
+	case path == "/bulk/registration/device/information/":
+		thisRef.bulkDeviceInformation(w, r)
+	case strings.HasPrefix(path, "/bulk/registration/device/friendly/configuration/"):
+		thisRef.bulkProductTemplate(w, pathParameters(path, "/bulk/registration/device/friendly/configuration/"))
+	case strings.HasPrefix(path, "/bulk/registration/configuration/"):
+		thisRef.bulkServiceConfig(w, pathParameters(path, "/bulk/registration/configuration/"))
+	case path == "/bulk/registration/register":
+		thisRef.bulkRegister(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (thisRef *Server) bulkDeviceInformation(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		RegistrationKey string `json:"BulkIdentificationCode"`
+		HardwareID      string `json:"HardwareId"`
+	}
+	if !readJSON(w, r, &request) {
+		return
+	}
+
+	if _, ok := thisRef.projects[request.RegistrationKey]; !ok {
+		writeReason(w, apiContracts.API_ERROR_CODE_REASON_NO_MATCHING_BULK_PROJECT)
+		return
+	}
+
+	writeStatus(w)
+}
+
+func (thisRef *Server) bulkProductTemplate(w http.ResponseWriter, parameters []string) {
+	project, ok := thisRef.projects[parameters[0]]
+	if !ok {
+		writeReason(w, apiContracts.API_ERROR_CODE_REASON_NO_MATCHING_BULK_PROJECT)
+		return
+	}
+
+	templateIDs := []string{}
+	for _, template := range project.Templates {
+		templateIDs = append(templateIDs, template.ID)
+	}
+
+	writeJSON(w, map[string]string{
+		"status":   apiContracts.API_ERROR_CODE_STATUS_TRUE,
+		"projects": strings.Join(templateIDs, ","),
+	})
+}
+
+func (thisRef *Server) bulkServiceConfig(w http.ResponseWriter, parameters []string) {
+	project, template, ok := thisRef.findTemplate(parameters[0])
+	if !ok {
+		writeReason(w, apiContracts.API_ERROR_CODE_REASON_NO_MATCHING_BULK_PROJECT)
+		return
+	}
+
+	enabled := "1"
+	if template.Disabled {
+		enabled = "0"
+	}
+
+	writeJSON(w, map[string]string{
+		"status":       apiContracts.API_ERROR_CODE_STATUS_TRUE,
+		"content_ip":   template.Hostname,
+		"content_port": template.Port,
+		"content_type": strconv.Itoa(template.Type),
+		"enabled":      enabled,
+		"project_id":   project.ProjectID,
+	})
+}
+
+func (thisRef *Server) bulkRegister(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		RegistrationKey string `json:"registration_key"`
+		HardwareID      string `json:"hardware_id"`
+		TemplateID      string `json:"project_id"`
+	}
+	if !readJSON(w, r, &request) {
+		return
+	}
+
+	project, template, ok := thisRef.findTemplate(request.TemplateID)
+	if !ok || project.RegistrationKey != request.RegistrationKey {
+		writeReason(w, apiContracts.API_ERROR_CODE_REASON_NO_MATCHING_BULK_PROJECT)
+		return
+	}
+
+	registration := request.TemplateID + "/" + request.HardwareID
+	if thisRef.registrations[registration] < project.PendingPolls {
+		thisRef.registrations[registration]++
+		writeJSON(w, map[string]string{"status": apiContracts.API_ERROR_CODE_STATUS_PENDING})
+		return
+	}
+
+	// registering again returns the same service
+	var device *Device
+	for _, existing := range thisRef.devices {
+		if existing.HardwareID == request.HardwareID && existing.Name == template.ID {
+			device = existing
+			break
+		}
+	}
+
+	if device == nil {
+		port, _ := strconv.Atoi(strings.Split(template.Port, ",")[0])
+		device = &Device{
+			UID:        thisRef.newUID(),
+			Secret:     newSecret(),
+			Name:       template.ID,
+			Type:       apiContracts.GetServiceType(apiContracts.DefaultServiceType, template.Type, 0, 0),
+			HardwareID: request.HardwareID,
+			Owner:      project.Owner,
+			Hostname:   template.Hostname,
+			Port:       port,
+			Disabled:   template.Disabled,
+		}
+		thisRef.devices[device.UID] = device
+	}
+
+	writeJSON(w, map[string]string{
+		"status":       apiContracts.API_ERROR_CODE_STATUS_TRUE,
+		"registration": fmt.Sprintf("%s/%s", project.RegistrationKey, template.ID),
+		"uid":          device.UID,
+		"secret":       device.Secret,
+	})
+}
+
+func (thisRef *Server) findTemplate(templateID string) (*BulkProject, BulkTemplate, bool) {
+	for _, project := range thisRef.projects {
+		for _, template := range project.Templates {
+			if template.ID == templateID {
+				return project, template, true
+			}
+		}
+	}
+
+	return nil, BulkTemplate{}, false
+}
+
+// pathParameters splits "/prefix/a/b/" into {"a", "b"}, always returning at least two values.
+func pathParameters(path string, prefix string) []string {
+	parameters := strings.Split(strings.Trim(strings.TrimPrefix(path, prefix), "/"), "/")
+	for len(parameters) < 2 {
+		parameters = append(parameters, "")
+	}
+
+	return parameters
+}
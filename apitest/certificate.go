@@ -0,0 +1,98 @@
+package apitest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"time"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+// serveCertificate signs the CSR of the request with the fake CA, or generates the key
+// itself when there is no CSR, like the API does.
+func (thisRef *Server) serveCertificate(w http.ResponseWriter, r *http.Request) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	if _, ok := thisRef.accountFromToken(r); !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var request apiContracts.CertificateRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+
+	commonName := request.Name
+	if commonName == "" {
+		commonName = request.ServiceID
+	}
+
+	response := apiContracts.CertificateResponse{CN: commonName}
+
+	var publicKey crypto.PublicKey
+	if request.CSR != "" {
+		block, _ := pem.Decode([]byte(request.CSR))
+		if block == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		if err != nil || csr.CheckSignature() != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		publicKey = csr.PublicKey
+	} else {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		keyAsDER, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		publicKey = key.Public()
+		response.Key = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyAsDER}))
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(thisRef.CertificateLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	if ip := net.ParseIP(request.IP); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else if request.IP != "" {
+		template.DNSNames = []string{request.IP}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, thisRef.caCertificate, publicKey, thisRef.caKey)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response.Certificate = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+
+	writeJSON(w, response)
+}
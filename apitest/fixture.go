@@ -0,0 +1,110 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"unicode/utf8"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+const Redacted = apiContracts.Redacted
+
+// Exchange is one request and its reply as stored in a golden file. The bodies that are not
+// valid UTF-8 go to the Base64 fields instead, byte for byte.
+type Exchange struct {
+	Method             string `json:"method"`
+	Path               string `json:"path"` // with the query, without scheme and host, redacted
+	RequestBody        string `json:"requestBody,omitempty"`
+	RequestBodyBase64  string `json:"requestBodyBase64,omitempty"`
+	StatusCode         int    `json:"statusCode"`
+	ContentType        string `json:"contentType,omitempty"`
+	ResponseBody       string `json:"responseBody,omitempty"`
+	ResponseBodyBase64 string `json:"responseBodyBase64,omitempty"`
+}
+
+// responseBody returns the recorded reply as it was sent, but redacted.
+func (thisRef Exchange) responseBody() []byte {
+	if thisRef.ResponseBodyBase64 != "" {
+		body, _ := base64.StdEncoding.DecodeString(thisRef.ResponseBodyBase64)
+		return body
+	}
+
+	return []byte(thisRef.ResponseBody)
+}
+
+// The JSON fields that are never written to a golden file, headers are not stored at all. The
+// registration key of the bulk calls goes by several names, `registration` starts with it.
+var redactedFields = []string{
+	"password", "secret", "token", "apikey", "authhash", "service_authhash", "key", "csr",
+	"registration_key", "bulkidentificationcode", "registration", "devicesecret", "projectsecret",
+}
+
+// LoadExchanges reads a golden file written by RecordingTransport.Save.
+func LoadExchanges(path string) ([]Exchange, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	exchanges := []Exchange{}
+	if err := json.Unmarshal(data, &exchanges); err != nil {
+		return nil, err
+	}
+
+	return exchanges, nil
+}
+
+func saveExchanges(path string, exchanges []Exchange) error {
+	data, err := json.MarshalIndent(exchanges, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// requestPath is the path as stored, without the secrets some endpoints take in it.
+func requestPath(r *http.Request) string {
+	if r.URL.RawQuery == "" {
+		return apiContracts.RedactPath(r.URL.Path)
+	}
+
+	return apiContracts.RedactPath(r.URL.Path + "?" + r.URL.RawQuery)
+}
+
+// readRequestBody reads the body and puts it back for the next transport.
+func readRequestBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+// NormalizeBody redacts a JSON body and re-encodes it with sorted keys so that equal
+// payloads compare equal, other bodies are kept as they are.
+func NormalizeBody(body []byte) string {
+	return apiContracts.RedactJSON(body, redactedFields)
+}
+
+// storedBody returns the normalized body, or its base64 when it is not valid UTF-8 since a
+// golden file is JSON.
+func storedBody(body []byte) (string, string) {
+	if !utf8.Valid(body) {
+		return "", base64.StdEncoding.EncodeToString(body)
+	}
+
+	return NormalizeBody(body), ""
+}
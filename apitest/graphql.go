@@ -0,0 +1,183 @@
+package apitest
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+// The fake only knows the queries the SDK sends.
+var (
+	gqlDevicePattern   = regexp.MustCompile(`device\(id:\s*"([^"]*)"\)`)
+	gqlServicePattern  = regexp.MustCompile(`service\(id:\s*"([^"]*)"\)`)
+	gqlServicesPattern = regexp.MustCompile(`service\(id:\s*\[([^\]]*)\]\)`)
+	gqlDevicesPattern  = regexp.MustCompile(`devices\(size:\s*(\d+),\s*from:\s*(\d+)\)`)
+)
+
+func (thisRef *Server) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	account, ok := thisRef.accountFromToken(r)
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte("Unauthorized"))
+		return
+	}
+
+	var request struct {
+		Query string `json:"query"`
+	}
+	if !readJSON(w, r, &request) {
+		return
+	}
+
+	switch {
+	case strings.Contains(request.Query, "applicationTypes"):
+		writeGraphQL(w, map[string]interface{}{"applicationTypes": thisRef.applicationTypes})
+
+	case gqlDevicePattern.MatchString(request.Query):
+		devices := []apiContracts.DefinedDevice{}
+		if device, ok := thisRef.devices[gqlDevicePattern.FindStringSubmatch(request.Query)[1]]; ok && device.Owner == account.Username {
+			devices = append(devices, thisRef.definedDevice(device))
+		}
+		writeGraphQL(w, map[string]interface{}{"login": map[string]interface{}{"device": devices}})
+
+	case gqlServicePattern.MatchString(request.Query):
+		services := []map[string]interface{}{}
+		if device, ok := thisRef.devices[gqlServicePattern.FindStringSubmatch(request.Query)[1]]; ok && device.Owner == account.Username {
+			services = append(services, map[string]interface{}{"application": applicationType(device.Type)})
+		}
+		writeGraphQL(w, map[string]interface{}{"login": map[string]interface{}{"service": services}})
+
+	case gqlServicesPattern.MatchString(request.Query):
+		services := []apiContracts.DefinedService{}
+		for _, id := range strings.Split(gqlServicesPattern.FindStringSubmatch(request.Query)[1], ",") {
+			if device, ok := thisRef.devices[strings.Trim(strings.TrimSpace(id), `"`)]; ok && device.Owner == account.Username {
+				services = append(services, apiContracts.DefinedService{ID: device.UID, Name: device.Name})
+			}
+		}
+		writeGraphQL(w, map[string]interface{}{"login": map[string]interface{}{"service": services}})
+
+	case gqlDevicesPattern.MatchString(request.Query):
+		match := gqlDevicesPattern.FindStringSubmatch(request.Query)
+		size, _ := strconv.Atoi(match[1])
+		from, _ := strconv.Atoi(match[2])
+
+		items := thisRef.listedDevices(account.Username)
+		total := len(items)
+		if from > total {
+			from = total
+		}
+		if from+size < total {
+			items = items[from : from+size]
+		} else {
+			items = items[from:]
+		}
+		writeGraphQL(w, map[string]interface{}{"login": map[string]interface{}{"devices": map[string]interface{}{"total": total, "items": items}}})
+
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, map[string]interface{}{"errors": []map[string]string{{"message": "unsupported query"}}})
+	}
+}
+
+// definedDevice lists every other service registered with the same hardware ID.
+func (thisRef *Server) definedDevice(device *Device) apiContracts.DefinedDevice {
+	definedDevice := apiContracts.DefinedDevice{
+		ID:       device.UID,
+		Name:     device.Name,
+		Services: []apiContracts.DefinedService{},
+	}
+
+	for _, service := range thisRef.ownedDevices(device.Owner) {
+		if service.UID != device.UID && service.HardwareID != "" && service.HardwareID == device.HardwareID {
+			definedDevice.Services = append(definedDevice.Services, apiContracts.DefinedService{ID: service.UID, Name: service.Name})
+		}
+	}
+
+	return definedDevice
+}
+
+// listedDevices groups the services of `username` by hardware ID into the items of the GraphQL
+// device listing, a service without a hardware ID is a device of its own.
+func (thisRef *Server) listedDevices(username string) []map[string]interface{} {
+	devices := []map[string]interface{}{}
+	byHardwareID := map[string]map[string]interface{}{}
+	for _, service := range thisRef.ownedDevices(username) {
+		state := apiContracts.DEVICE_STATE_INACTIVE
+		if service.Active {
+			state = apiContracts.DEVICE_STATE_ACTIVE
+		}
+
+		device, ok := byHardwareID[service.HardwareID]
+		if !ok || service.HardwareID == "" {
+			device = map[string]interface{}{
+				"id":       service.UID,
+				"name":     service.Name,
+				"state":    apiContracts.DEVICE_STATE_INACTIVE,
+				"owner":    map[string]string{"email": service.Owner},
+				"services": []map[string]interface{}{},
+			}
+			devices = append(devices, device)
+			if service.HardwareID != "" {
+				byHardwareID[service.HardwareID] = device
+			}
+		}
+		if service.Active {
+			device["state"] = apiContracts.DEVICE_STATE_ACTIVE
+		}
+
+		sessions := []map[string]string{}
+		for _, id := range thisRef.connectionIDs(service.UID) {
+			sessions = append(sessions, map[string]string{"id": id})
+		}
+
+		device["services"] = append(device["services"].([]map[string]interface{}), map[string]interface{}{
+			"id":          service.UID,
+			"name":        service.Name,
+			"state":       state,
+			"application": applicationType(service.Type),
+			"sessions":    sessions,
+		})
+	}
+
+	return devices
+}
+
+// connectionIDs are the open connections to `serviceID`, sorted.
+func (thisRef *Server) connectionIDs(serviceID string) []string {
+	ids := []string{}
+	for id, connection := range thisRef.connections {
+		if connection.TargetUID == serviceID {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+// applicationType reads the application type back from the first two bytes of a service type.
+func applicationType(serviceType string) int {
+	parts := strings.Split(serviceType, ":")
+	if len(parts) < 2 {
+		return apiContracts.InvalidApplicationType
+	}
+
+	value, err := strconv.ParseInt(parts[0]+parts[1], 16, 32)
+	if err != nil {
+		return apiContracts.InvalidApplicationType
+	}
+
+	return int(value)
+}
+
+func writeGraphQL(w http.ResponseWriter, data interface{}) {
+	writeJSON(w, map[string]interface{}{"data": data})
+}
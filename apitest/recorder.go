@@ -0,0 +1,79 @@
+package apitest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// RecordingTransport passes every request to `next` and keeps the exchange, redacted,
+// for Save. The SDK clients take it through their NewXWithTransport constructors.
+type RecordingTransport struct {
+	path string
+	next http.RoundTripper
+
+	mutex     sync.Mutex
+	exchanges []Exchange
+}
+
+// NewRecordingTransport records into the golden file at `path`, `next` is
+// http.DefaultTransport when nil.
+func NewRecordingTransport(path string, next http.RoundTripper) *RecordingTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &RecordingTransport{
+		path:      path,
+		next:      next,
+		exchanges: []Exchange{},
+	}
+}
+
+func (thisRef *RecordingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	requestBody, err := readRequestBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := thisRef.next.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+
+	exchange := Exchange{
+		Method:      r.Method,
+		Path:        requestPath(r),
+		StatusCode:  response.StatusCode,
+		ContentType: response.Header.Get("Content-Type"),
+	}
+	exchange.RequestBody, exchange.RequestBodyBase64 = storedBody(requestBody)
+	exchange.ResponseBody, exchange.ResponseBodyBase64 = storedBody(responseBody)
+
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	thisRef.exchanges = append(thisRef.exchanges, exchange)
+
+	return response, nil
+}
+
+func (thisRef *RecordingTransport) Exchanges() []Exchange {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	return append([]Exchange{}, thisRef.exchanges...)
+}
+
+// Save writes everything recorded so far to the golden file.
+func (thisRef *RecordingTransport) Save() error {
+	return saveExchanges(thisRef.path, thisRef.Exchanges())
+}
@@ -0,0 +1,95 @@
+package apitest
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// ReplayTransport answers every request from a golden file without touching the network.
+// A request matches an exchange on the method, the redacted path and the normalized body, identical
+// requests get the recorded replies in order. Anything else fails the request.
+type ReplayTransport struct {
+	mutex     sync.Mutex
+	exchanges []Exchange
+	used      []bool
+}
+
+func NewReplayTransport(path string) (*ReplayTransport, error) {
+	exchanges, err := LoadExchanges(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReplayTransportFromExchanges(exchanges), nil
+}
+
+func NewReplayTransportFromExchanges(exchanges []Exchange) *ReplayTransport {
+	return &ReplayTransport{
+		exchanges: exchanges,
+		used:      make([]bool, len(exchanges)),
+	}
+}
+
+func (thisRef *ReplayTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	requestBody, err := readRequestBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	path := requestPath(r)
+	normalizedBody, base64Body := storedBody(requestBody)
+
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	for i, exchange := range thisRef.exchanges {
+		if thisRef.used[i] || exchange.Method != r.Method || exchange.Path != path || exchange.RequestBody != normalizedBody || exchange.RequestBodyBase64 != base64Body {
+			continue
+		}
+
+		thisRef.used[i] = true
+
+		return newReplayResponse(r, exchange), nil
+	}
+
+	return nil, fmt.Errorf("apitest: no recorded exchange for %s %s %s", r.Method, path, normalizedBody)
+}
+
+// Unused returns the exchanges no request asked for yet.
+func (thisRef *ReplayTransport) Unused() []Exchange {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	unused := []Exchange{}
+	for i, exchange := range thisRef.exchanges {
+		if !thisRef.used[i] {
+			unused = append(unused, exchange)
+		}
+	}
+
+	return unused
+}
+
+func newReplayResponse(r *http.Request, exchange Exchange) *http.Response {
+	header := http.Header{}
+	if exchange.ContentType != "" {
+		header.Set("Content-Type", exchange.ContentType)
+	}
+
+	body := exchange.responseBody()
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.StatusCode, http.StatusText(exchange.StatusCode)),
+		StatusCode:    exchange.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       r,
+	}
+}
@@ -0,0 +1,368 @@
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+const (
+	reasonUserOrPasswordInvalid = "[0102] The username or password are invalid"
+	reasonDeviceNotFound        = "[0806] device not found"
+	reasonDuplicateName         = "[0807] duplicate name"
+	reasonServiceNotFound       = "[0861] service not found for UID"
+	reasonConnectionNotFound    = "connection not found"
+	reasonBadAPIKey             = "invalid api key"
+)
+
+func (thisRef *Server) serveREST(w http.ResponseWriter, r *http.Request, path string) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	if !thisRef.isKnownAPIKey(r.Header.Get("apikey")) {
+		writeReason(w, reasonBadAPIKey)
+		return
+	}
+
+	switch {
+	case path == "/user/login":
+		thisRef.login(w, r)
+	case path == "/user/login/authhash":
+		thisRef.loginWithAuthHash(w, r)
+	case strings.HasPrefix(path, "/bulk/"):
+		thisRef.serveBulk(w, r, path)
+	default:
+		account, ok := thisRef.accountFromToken(r)
+		if !ok {
+			writeReason(w, apiContracts.API_ERROR_CODE_REASON_MISSING_API_TOKEN)
+			return
+		}
+
+		thisRef.serveDevices(w, r, path, account)
+	}
+}
+
+func (thisRef *Server) serveDevices(w http.ResponseWriter, r *http.Request, path string, account *Account) {
+	switch {
+	case path == "/device/list/all":
+		thisRef.listDevices(w, account)
+	case path == "/device/create":
+		thisRef.createDevice(w, r, account)
+	case strings.HasPrefix(path, "/device/address/"):
+		writeJSON(w, map[string]string{"status": apiContracts.API_ERROR_CODE_STATUS_TRUE, "deviceaddress": thisRef.newUID()})
+	case path == "/device/register":
+		thisRef.registerDevice(w, r, account)
+	case path == "/device/delete":
+		var request struct {
+			UID string `json:"deviceaddress"`
+		}
+		if !readJSON(w, r, &request) {
+			return
+		}
+		thisRef.deleteDevice(w, request.UID, account)
+	case strings.HasPrefix(path, "/developer/device/delete/registered/"):
+		thisRef.deleteDevice(w, strings.TrimPrefix(path, "/developer/device/delete/registered/"), account)
+	case strings.HasPrefix(path, "/developer/devices/transfer/"):
+		thisRef.transferDevice(w, r, strings.TrimPrefix(path, "/developer/devices/transfer/"), account)
+	case path == "/device/connect":
+		thisRef.connect(w, r, account)
+	case path == "/device/connect/stop":
+		thisRef.disconnect(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (thisRef *Server) login(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if !readJSON(w, r, &request) {
+		return
+	}
+
+	account, ok := thisRef.accounts[request.Username]
+	if !ok {
+		writeReason(w, apiContracts.API_ERROR_CODE_REASON_MISSING_USER)
+		return
+	}
+	if account.Password != request.Password {
+		writeReason(w, reasonUserOrPasswordInvalid)
+		return
+	}
+	if account.MFA != "" {
+		writeJSON(w, map[string]string{"status": apiContracts.API_ERROR_CODE_STATUS_FALSE, "code": account.MFA})
+		return
+	}
+
+	thisRef.writeLogin(w, account)
+}
+
+func (thisRef *Server) loginWithAuthHash(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Username string `json:"username"`
+		AuthHash string `json:"authhash"`
+	}
+	if !readJSON(w, r, &request) {
+		return
+	}
+
+	account, ok := thisRef.accounts[request.Username]
+	if !ok {
+		writeReason(w, apiContracts.API_ERROR_CODE_REASON_MISSING_USER)
+		return
+	}
+	if account.AuthHash != request.AuthHash {
+		writeReason(w, reasonUserOrPasswordInvalid)
+		return
+	}
+
+	thisRef.writeLogin(w, account)
+}
+
+func (thisRef *Server) writeLogin(w http.ResponseWriter, account *Account) {
+	writeJSON(w, map[string]string{
+		"status":           apiContracts.API_ERROR_CODE_STATUS_TRUE,
+		"service_authhash": account.AuthHash,
+		"guid":             account.UserID,
+		"token":            thisRef.issueToken(account.Username),
+	})
+}
+
+func (thisRef *Server) listDevices(w http.ResponseWriter, account *Account) {
+	response := apiContracts.DeviceListAllResponse{
+		Status:  apiContracts.API_ERROR_CODE_STATUS_TRUE,
+		Devices: []apiContracts.Device{},
+	}
+
+	for _, device := range thisRef.ownedDevices(account.Username) {
+		state := apiContracts.DEVICE_STATE_INACTIVE
+		if device.Active {
+			state = apiContracts.DEVICE_STATE_ACTIVE
+		}
+
+		response.Devices = append(response.Devices, apiContracts.Device{
+			DeviceAddress: device.UID,
+			DeviceType:    device.Type,
+			DeviceAlias:   device.Name,
+			OwnerUserName: device.Owner,
+			DeviceState:   state,
+		})
+	}
+
+	writeJSON(w, response)
+}
+
+func (thisRef *Server) createDevice(w http.ResponseWriter, r *http.Request, account *Account) {
+	var request struct {
+		UID  string `json:"deviceaddress"`
+		Type string `json:"devicetype"`
+	}
+	if !readJSON(w, r, &request) {
+		return
+	}
+
+	if request.UID == "" {
+		writeReason(w, apiContracts.API_ERROR_CODE_REASON_BAD_DEVICE_ADDRESS)
+		return
+	}
+
+	if device, ok := thisRef.devices[request.UID]; ok && device.Owner != account.Username {
+		writeReason(w, reasonDeviceNotFound)
+		return
+	}
+
+	thisRef.devices[request.UID] = &Device{
+		UID:    request.UID,
+		Secret: newSecret(),
+		Type:   request.Type,
+		Owner:  account.Username,
+	}
+
+	writeStatus(w)
+}
+
+func (thisRef *Server) registerDevice(w http.ResponseWriter, r *http.Request, account *Account) {
+	var request struct {
+		UID        string `json:"deviceaddress"`
+		Type       string `json:"devicetype"`
+		Name       string `json:"devicealias"`
+		HardwareID string `json:"hardwareid"`
+	}
+	if !readJSON(w, r, &request) {
+		return
+	}
+
+	if request.UID == "" {
+		writeReason(w, apiContracts.API_ERROR_CODE_REASON_BAD_DEVICE_ADDRESS)
+		return
+	}
+
+	device, ok := thisRef.devices[request.UID]
+	if ok && device.Owner != account.Username {
+		writeReason(w, reasonDeviceNotFound)
+		return
+	}
+
+	for _, other := range thisRef.ownedDevices(account.Username) {
+		if other.UID != request.UID && other.Name == request.Name {
+			writeReason(w, reasonDuplicateName)
+			return
+		}
+	}
+
+	if !ok {
+		device = &Device{UID: request.UID, Secret: newSecret(), Owner: account.Username}
+		thisRef.devices[request.UID] = device
+	}
+	device.Type = request.Type
+	device.Name = request.Name
+	device.HardwareID = request.HardwareID
+
+	writeJSON(w, map[string]interface{}{
+		"status": apiContracts.API_ERROR_CODE_STATUS_TRUE,
+		"secret": formatSecret(device.Secret),
+		"device": map[string]string{
+			"deviceaddress": device.UID,
+			"name":          device.Name,
+			"owner":         device.Owner,
+			"devicetype":    device.Type,
+		},
+	})
+}
+
+func (thisRef *Server) deleteDevice(w http.ResponseWriter, uid string, account *Account) {
+	device, ok := thisRef.devices[uid]
+	if !ok || device.Owner != account.Username {
+		writeReason(w, reasonServiceNotFound)
+		return
+	}
+
+	delete(thisRef.devices, uid)
+
+	writeStatus(w)
+}
+
+func (thisRef *Server) transferDevice(w http.ResponseWriter, r *http.Request, uid string, account *Account) {
+	var request struct {
+		User string `json:"user"`
+	}
+	if !readJSON(w, r, &request) {
+		return
+	}
+
+	device, ok := thisRef.devices[uid]
+	if !ok || device.Owner != account.Username {
+		writeReason(w, reasonServiceNotFound)
+		return
+	}
+	if _, ok := thisRef.accounts[request.User]; !ok {
+		writeReason(w, apiContracts.API_ERROR_CODE_REASON_MISSING_USER)
+		return
+	}
+
+	device.Owner = request.User
+
+	writeStatus(w)
+}
+
+func (thisRef *Server) connect(w http.ResponseWriter, r *http.Request, account *Account) {
+	var request apiContracts.CreateProxyRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+
+	device, ok := thisRef.devices[request.DeviceAddress]
+	if !ok || device.Owner != account.Username {
+		writeReason(w, reasonServiceNotFound)
+		return
+	}
+
+	host, port := thisRef.proxyAddress()
+	connection := apiContracts.ProxyConnectionInfo{
+		ConnectionID:     strings.ToUpper(randomHex(16)),
+		SessionID:        strings.ToUpper(randomHex(20)),
+		TargetUID:        device.UID,
+		ProxyServer:      host,
+		ProxyPort:        port,
+		Proxy:            fmt.Sprintf("http://%s:%s", host, port),
+		ProxyURL:         fmt.Sprintf("%s:%s", host, port),
+		P2PConnected:     true,
+		ServiceConnected: true,
+		LifeLeft:         int(thisRef.ConnectionLifetime.Seconds()),
+	}
+	thisRef.connections[connection.ConnectionID] = connection
+
+	writeJSON(w, apiContracts.CreateProxyResponse{
+		Status:     apiContracts.API_ERROR_CODE_STATUS_TRUE,
+		Connection: connection,
+	})
+}
+
+func (thisRef *Server) disconnect(w http.ResponseWriter, r *http.Request) {
+	var request apiContracts.DeleteProxyRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+
+	connection, ok := thisRef.connections[request.ConnectionID]
+	if !ok || connection.TargetUID != request.DeviceAddress {
+		writeReason(w, reasonConnectionNotFound)
+		return
+	}
+
+	delete(thisRef.connections, request.ConnectionID)
+
+	writeStatus(w)
+}
+
+func (thisRef *Server) isKnownAPIKey(apiKey string) bool {
+	for _, account := range thisRef.accounts {
+		if account.APIKey == "" || account.APIKey == apiKey {
+			return true
+		}
+	}
+
+	return len(thisRef.accounts) == 0
+}
+
+// formatSecret splits the secret in bytes like the API does, "AB:CD:..."
+func formatSecret(secret string) string {
+	parts := []string{}
+	for i := 0; i+2 <= len(secret); i += 2 {
+		parts = append(parts, strings.ToUpper(secret[i:i+2]))
+	}
+
+	return strings.Join(parts, ":")
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, value interface{}) bool {
+	body, err := ioutil.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(body, value)
+	}
+	if err != nil {
+		writeReason(w, fmt.Sprintf("invalid request: %s", err))
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeStatus(w http.ResponseWriter) {
+	writeJSON(w, map[string]string{"status": apiContracts.API_ERROR_CODE_STATUS_TRUE})
+}
+
+func writeReason(w http.ResponseWriter, reason string) {
+	writeJSON(w, map[string]string{"status": apiContracts.API_ERROR_CODE_STATUS_FALSE, "reason": reason})
+}
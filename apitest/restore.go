@@ -0,0 +1,68 @@
+package apitest
+
+import (
+	"net/http"
+)
+
+func (thisRef *Server) serveRestore(w http.ResponseWriter, r *http.Request) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	account, ok := thisRef.accountFromToken(r)
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var request struct {
+		DeviceID  string `json:"deviceId"`
+		MachineID string `json:"machineId"`
+	}
+	if !readJSON(w, r, &request) {
+		return
+	}
+
+	device, ok := thisRef.devices[request.DeviceID]
+	if !ok || device.Owner != account.Username {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if device.Active {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	type restoreService struct {
+		UID      string `json:"uid"`
+		Secret   string `json:"secret"`
+		Name     string `json:"name"`
+		Type     int    `json:"type"`
+		Hostname string `json:"hostname"`
+		Port     int    `json:"port"`
+		Enabled  bool   `json:"enabled"`
+	}
+
+	services := []restoreService{}
+	for _, service := range thisRef.definedDevice(device).Services {
+		service := thisRef.devices[service.ID]
+		services = append(services, restoreService{
+			UID:      service.UID,
+			Secret:   service.Secret,
+			Name:     service.Name,
+			Type:     applicationType(service.Type),
+			Hostname: service.Hostname,
+			Port:     service.Port,
+			Enabled:  !service.Disabled,
+		})
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"device": map[string]string{
+			"uid":        device.UID,
+			"secret":     device.Secret,
+			"name":       device.Name,
+			"hardwareId": device.HardwareID,
+		},
+		"services": services,
+	})
+}
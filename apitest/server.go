@@ -0,0 +1,460 @@
+package apitest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+// The paths the fake serves, mirroring the hosts of the DEFAULT_API_* URLs.
+const (
+	RESTPath        = "/apv/v27"
+	GraphQLPath     = "/graphql/v1"
+	RestorePath     = "/v1/restore"
+	CertificatePath = "/v1/certificate"
+)
+
+const (
+	DefaultCertificateLifetime = 24 * time.Hour
+	DefaultConnectionLifetime  = 24 * time.Hour
+)
+
+// Account is a remote.it user known to the fake server. Empty IDs are generated by AddAccount.
+type Account struct {
+	Username string
+	Password string
+	APIKey   string // any API key is accepted when empty
+	UserID   string
+	AuthHash string
+	MFA      string // one of the API_ERROR_CODE_REASON_MFA_* codes, password logins then fail
+}
+
+// Device is a registered device or service. Services of a device share its HardwareID.
+type Device struct {
+	UID        string
+	Secret     string
+	Name       string
+	Type       string // service type as sent on registration, see `apiContracts.GetServiceType`
+	HardwareID string
+	Owner      string // username
+	Hostname   string
+	Port       int
+	Disabled   bool
+	Active     bool // an active device can't be restored
+}
+
+// BulkProject is a bulk registration project, devices registering with its key get one
+// service per template.
+type BulkProject struct {
+	RegistrationKey string
+	ProjectID       string // the `project_id` of its service configs
+	Owner           string // username of the account the registered services belong to
+	Templates       []BulkTemplate
+	PendingPolls    int // how many times each registration reports `pending` before it completes
+}
+
+type BulkTemplate struct {
+	ID       string
+	Type     int
+	Hostname string
+	Port     string // comma separated for multi-port services
+	Disabled bool
+}
+
+// Failure replaces the reply of an endpoint.
+type Failure struct {
+	StatusCode int           // HTTP status code, 200 when zero
+	Reason     string        // REST `reason`, sent with a `false` status
+	Body       string        // raw reply, takes precedence over Reason
+	Delay      time.Duration // wait before replying, use with a short client timeout
+	Times      int           // how many requests fail, every request when zero
+}
+
+// Server is an in-process fake of the remote.it REST, GraphQL, restore and certificate APIs,
+// it keeps every account, device and connection in memory.
+type Server struct {
+	URL            string // use instead of DEFAULT_API_URL
+	GraphQLURL     string // use instead of DEFAULT_API_GRAPHQL_URL
+	RestoreURL     string // use instead of DEFAULT_API_RESTORE_URL
+	CertificateURL string // use instead of DEFAULT_API_CERTIFICATE_URL
+
+	CertificateLifetime time.Duration
+	ConnectionLifetime  time.Duration // `lifeLeft` of the new connections
+
+	server *httptest.Server
+
+	mutex            sync.Mutex
+	nextID           int
+	accounts         map[string]*Account // by username
+	tokens           map[string]string   // token -> username
+	devices          map[string]*Device  // by UID
+	projects         map[string]*BulkProject
+	registrations    map[string]int // "templateID/hardwareID" -> polls so far
+	connections      map[string]apiContracts.ProxyConnectionInfo
+	applicationTypes []apiContracts.ApplicationType
+	failures         map[string]*Failure // by endpoint prefix
+	requests         []string
+
+	caKey         *ecdsa.PrivateKey
+	caCertificate *x509.Certificate
+}
+
+func NewServer() *Server {
+	thisRef := &Server{
+		CertificateLifetime: DefaultCertificateLifetime,
+		ConnectionLifetime:  DefaultConnectionLifetime,
+		accounts:            map[string]*Account{},
+		tokens:              map[string]string{},
+		devices:             map[string]*Device{},
+		projects:            map[string]*BulkProject{},
+		registrations:       map[string]int{},
+		connections:         map[string]apiContracts.ProxyConnectionInfo{},
+		applicationTypes:    defaultApplicationTypes(),
+		failures:            map[string]*Failure{},
+	}
+
+	thisRef.caKey, thisRef.caCertificate = newCertificateAuthority()
+
+	thisRef.server = httptest.NewServer(thisRef)
+	thisRef.URL = thisRef.server.URL + RESTPath
+	thisRef.GraphQLURL = thisRef.server.URL + GraphQLPath
+	thisRef.RestoreURL = thisRef.server.URL + RestorePath
+	thisRef.CertificateURL = thisRef.server.URL + CertificatePath
+
+	return thisRef
+}
+
+// CertificateAuthority is the issuer of every certificate the fake signs.
+func (thisRef *Server) CertificateAuthority() *x509.Certificate {
+	return thisRef.caCertificate
+}
+
+func (thisRef *Server) Close() {
+	thisRef.server.Close()
+}
+
+// AddAccount stores `account`, deriving the missing user ID and auth hash from the
+// credentials so they are the same on every server, as the SDK caches them globally.
+func (thisRef *Server) AddAccount(account Account) Account {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	if account.UserID == "" {
+		account.UserID = hashHex(account.Username)[:32]
+	}
+	if account.AuthHash == "" {
+		account.AuthHash = hashHex(account.Username + ":" + account.Password)
+	}
+
+	thisRef.accounts[account.Username] = &account
+
+	return account
+}
+
+// Token logs `username` in without a request, for clients that only take a token.
+func (thisRef *Server) Token(username string) string {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	if _, ok := thisRef.accounts[username]; !ok {
+		return ""
+	}
+
+	return thisRef.issueToken(username)
+}
+
+// RevokeTokens expires every token of `username`, like the API does after a while.
+func (thisRef *Server) RevokeTokens(username string) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	for token, owner := range thisRef.tokens {
+		if owner == username {
+			delete(thisRef.tokens, token)
+		}
+	}
+}
+
+// AddDevice stores `device`, generating the missing UID and secret.
+func (thisRef *Server) AddDevice(device Device) Device {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	if device.UID == "" {
+		device.UID = thisRef.newUID()
+	}
+	if device.Secret == "" {
+		device.Secret = newSecret()
+	}
+
+	thisRef.devices[device.UID] = &device
+
+	return device
+}
+
+func (thisRef *Server) Device(uid string) (Device, bool) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	device, ok := thisRef.devices[uid]
+	if !ok {
+		return Device{}, false
+	}
+
+	return *device, true
+}
+
+// Devices returns the devices owned by `username`, ordered by UID.
+func (thisRef *Server) Devices(username string) []Device {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	devices := []Device{}
+	for _, device := range thisRef.ownedDevices(username) {
+		devices = append(devices, *device)
+	}
+
+	return devices
+}
+
+func (thisRef *Server) AddBulkProject(project BulkProject) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	thisRef.projects[project.RegistrationKey] = &project
+}
+
+func (thisRef *Server) SetApplicationTypes(applicationTypes []apiContracts.ApplicationType) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	thisRef.applicationTypes = applicationTypes
+}
+
+// Connections returns the open proxy connections.
+func (thisRef *Server) Connections() []apiContracts.ProxyConnectionInfo {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	connections := []apiContracts.ProxyConnectionInfo{}
+	for _, connection := range thisRef.connections {
+		connections = append(connections, connection)
+	}
+
+	return connections
+}
+
+// Fail makes every endpoint starting with `path` reply with `failure`, the longest matching
+// `path` wins. REST paths are the ones passed to `Client.Post` and `Client.Get`, the other
+// APIs use GraphQLPath, RestorePath and CertificatePath.
+func (thisRef *Server) Fail(path string, failure Failure) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	thisRef.failures[path] = &failure
+}
+
+func (thisRef *Server) ClearFailures() {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	thisRef.failures = map[string]*Failure{}
+}
+
+// Requests returns every request received so far as "METHOD /path".
+func (thisRef *Server) Requests() []string {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	return append([]string{}, thisRef.requests...)
+}
+
+func (thisRef *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	if strings.HasPrefix(path, RESTPath+"/") {
+		path = strings.TrimPrefix(path, RESTPath)
+	}
+
+	thisRef.mutex.Lock()
+	thisRef.requests = append(thisRef.requests, r.Method+" "+path)
+	failure := thisRef.takeFailure(path)
+	thisRef.mutex.Unlock()
+
+	if failure != nil {
+		writeFailure(w, *failure)
+		return
+	}
+
+	switch {
+	case r.URL.Path == GraphQLPath:
+		thisRef.serveGraphQL(w, r)
+	case r.URL.Path == RestorePath:
+		thisRef.serveRestore(w, r)
+	case r.URL.Path == CertificatePath:
+		thisRef.serveCertificate(w, r)
+	case strings.HasPrefix(r.URL.Path, RESTPath+"/"):
+		thisRef.serveREST(w, r, path)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// takeFailure uses up the failure of the longest prefix of `path`, so `/device/list` can fail
+// differently from `/device`.
+func (thisRef *Server) takeFailure(path string) *Failure {
+	var prefix string
+	var failure *Failure
+	for candidate, candidateFailure := range thisRef.failures {
+		if strings.HasPrefix(path, candidate) && (failure == nil || len(candidate) > len(prefix)) {
+			prefix, failure = candidate, candidateFailure
+		}
+	}
+
+	if failure == nil {
+		return nil
+	}
+
+	taken := *failure
+	if failure.Times > 0 {
+		failure.Times--
+		if failure.Times == 0 {
+			delete(thisRef.failures, prefix)
+		}
+	}
+
+	return &taken
+}
+
+func writeFailure(w http.ResponseWriter, failure Failure) {
+	time.Sleep(failure.Delay)
+
+	statusCode := failure.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	body := failure.Body
+	if body == "" && failure.Reason != "" {
+		body = fmt.Sprintf(`{"status":"false","reason":%q}`, failure.Reason)
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	w.WriteHeader(statusCode)
+	w.Write([]byte(body))
+}
+
+// The helpers below expect the mutex to be held.
+
+func (thisRef *Server) issueToken(username string) string {
+	token := randomHex(32)
+	thisRef.tokens[token] = username
+
+	return token
+}
+
+// accountFromToken returns the account logged in with the `token` header.
+func (thisRef *Server) accountFromToken(r *http.Request) (*Account, bool) {
+	username, ok := thisRef.tokens[r.Header.Get("token")]
+	if !ok {
+		return nil, false
+	}
+
+	account, ok := thisRef.accounts[username]
+
+	return account, ok
+}
+
+func (thisRef *Server) ownedDevices(username string) []*Device {
+	devices := []*Device{}
+	for _, device := range thisRef.devices {
+		if device.Owner == username {
+			devices = append(devices, device)
+		}
+	}
+
+	sort.Slice(devices, func(i, j int) bool { return devices[i].UID < devices[j].UID })
+
+	return devices
+}
+
+func (thisRef *Server) newUID() string {
+	thisRef.nextID++
+	return fmt.Sprintf("80:00:00:00:01:%02X:%02X:%02X", byte(thisRef.nextID>>16), byte(thisRef.nextID>>8), byte(thisRef.nextID))
+}
+
+func newSecret() string {
+	return strings.ToUpper(randomHex(20))
+}
+
+func hashHex(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:])
+}
+
+func randomHex(size int) string {
+	data := make([]byte, size)
+	rand.Read(data)
+
+	return hex.EncodeToString(data)
+}
+
+func defaultApplicationTypes() []apiContracts.ApplicationType {
+	return []apiContracts.ApplicationType{
+		{ID: 1, Name: "TCP", Description: "Generic TCP", Protocol: "TCP"},
+		{ID: 7, Name: "HTTP", Description: "Web", Port: 80, Proxy: true, Protocol: "TCP"},
+		{ID: 8, Name: "HTTPS", Description: "Secure web", Port: 443, Proxy: true, Protocol: "TCP"},
+		{ID: 28, Name: "SSH", Description: "Secure shell", Port: 22, Protocol: "TCP"},
+		{ID: 35, Name: "Bulk Service", Description: "Device", Protocol: "TCP"},
+	}
+}
+
+func newCertificateAuthority() (*ecdsa.PrivateKey, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "apitest CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(10 * 365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		panic(err)
+	}
+
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		panic(err)
+	}
+
+	return key, certificate
+}
+
+// proxyAddress is where the connections point to, the fake server itself so that
+// proxy health checks succeed.
+func (thisRef *Server) proxyAddress() (string, string) {
+	host, port, _ := net.SplitHostPort(thisRef.server.Listener.Addr().String())
+	return host, port
+}
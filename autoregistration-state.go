@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+// AutoRegistrationStateStore persists the progress of `AutoRegisterIfNeeded` so an
+// interrupted run resumes where it left off instead of registering everything again.
+type AutoRegistrationStateStore interface {
+	Load() (apiContracts.AutoRegistrationState, bool, errorx.Error)
+	Save(state apiContracts.AutoRegistrationState) errorx.Error
+	Clear() errorx.Error
+}
+
+func NewFileAutoRegistrationStateStore(path string) AutoRegistrationStateStore {
+	return &fileAutoRegistrationStateStore{
+		path: path,
+	}
+}
+
+type fileAutoRegistrationStateStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+func (thisRef *fileAutoRegistrationStateStore) Load() (apiContracts.AutoRegistrationState, bool, errorx.Error) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	data, err := ioutil.ReadFile(thisRef.path)
+	if os.IsNotExist(err) {
+		return apiContracts.AutoRegistrationState{}, false, nil
+	}
+	if err != nil {
+		return apiContracts.AutoRegistrationState{}, false, apiContracts.NewErrorFromErr(apiContracts.ErrAutoreg_CantLoadState.Code(), err)
+	}
+
+	var state apiContracts.AutoRegistrationState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return apiContracts.AutoRegistrationState{}, false, apiContracts.NewErrorFromErr(apiContracts.ErrAutoreg_CantLoadState.Code(), err)
+	}
+
+	if state.Registered == nil {
+		state.Registered = map[string]apiContracts.ServiceCredentials{}
+	}
+
+	return state, true, nil
+}
+
+func (thisRef *fileAutoRegistrationStateStore) Save(state apiContracts.AutoRegistrationState) errorx.Error {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	data, err := json.MarshalIndent(state, "", "\t")
+	if err != nil {
+		return apiContracts.NewErrorFromErr(apiContracts.ErrAutoreg_CantSaveState.Code(), err)
+	}
+
+	// the state holds service secrets, keep it private and never leave a half written file
+	if err := writeFileAtomic(thisRef.path, data, 0600); err != nil {
+		return apiContracts.NewErrorFromErr(apiContracts.ErrAutoreg_CantSaveState.Code(), err)
+	}
+
+	return nil
+}
+
+func (thisRef *fileAutoRegistrationStateStore) Clear() errorx.Error {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	if err := os.Remove(thisRef.path); err != nil && !os.IsNotExist(err) {
+		return apiContracts.NewErrorFromErr(apiContracts.ErrAutoreg_CantSaveState.Code(), err)
+	}
+
+	return nil
+}
+
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	tmpFile, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpFile.Name()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
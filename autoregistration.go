@@ -1,10 +1,14 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
+	"time"
 
 	apiContracts "github.com/remoteit/sdk-go/contracts"
 	errorx "github.com/remoteit/systemkit-errorx"
@@ -15,6 +19,13 @@ type AutoRegistration interface {
 	GetProductTemplate(registrationKey string, deviceUniquID string) ([]string, bool, string, errorx.Error)
 	GetServiceConfigFromTemplateID(serviceID string, hardwareID string) (apiContracts.ServiceConfigResponse, errorx.Error)
 	RegisterService(serviceID string, uniqueDeviceID string, registrationKey string) (apiContracts.ServiceCredentials, bool, errorx.Error)
+
+	AutoRegisterIfNeeded(request apiContracts.AutoRegistrationRequest) ([]apiContracts.Service, errorx.Error)
+
+	ReportComponentVersions(uid string, components []apiContracts.ComponentVersion) errorx.Error
+	GetProjectEnablement(registrationKey string, hardwareID string) (apiContracts.ProjectEnablement, errorx.Error)
+	GetProvisioning(registrationKey string, hardwareID string) (apiContracts.ProvisioningInfo, errorx.Error)
+	DownloadProvisioning(registrationKey string, hardwareID string, checksum string, writer io.Writer) (int64, errorx.Error)
 }
 
 func NewAutoRegistration(apiClient Client) AutoRegistration {
@@ -23,20 +34,32 @@ func NewAutoRegistration(apiClient Client) AutoRegistration {
 	}
 }
 
+func NewAutoRegistrationWithStateStore(apiClient Client, stateStore AutoRegistrationStateStore) AutoRegistration {
+	return &autoRegistration{
+		apiClient:  apiClient,
+		stateStore: stateStore,
+	}
+}
+
 type autoRegistration struct {
-	apiClient Client
+	apiClient  Client
+	stateStore AutoRegistrationStateStore
 }
 
 // X ->	?????????????																		-> SendDeviceInfo
 // X -> ProjectListURL				="/bulk/registration/device/friendly/configuration"		-> ListServiceIDs
 // X -> ProvisionConfig				="/bulk/registration/configuration"						-> GetServiceConfig
 // X -> BulkRegisterURL				="/bulk/registration/register"							-> RegisterService
-// - -> ComponentVersionURL			="/device/component/version"
-// ? -> ProjectEnablementGet		="/device/enablement"
-// - -> ProvisionGet				="/project/provisioning"
-// - -> ProvisionDownloadDirect		="/project/provisioning/download"
+// X -> ComponentVersionURL			="/device/component/version"							-> ReportComponentVersions
+// X -> ProjectEnablementGet		="/device/enablement"									-> GetProjectEnablement
+// X -> ProvisionGet				="/project/provisioning"								-> GetProvisioning
+// X -> ProvisionDownloadDirect		="/project/provisioning/download"						-> DownloadProvisioning
+
+func (thisRef autoRegistration) SendDeviceInfo(registrationKey string, hardwareID string, cpuID string, macAddress string, version string, platformOSName string) (errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "AutoRegistration.SendDeviceInfo")
+	defer op.end(&errx)
 
-func (thisRef autoRegistration) SendDeviceInfo(registrationKey string, hardwareID string, cpuID string, macAddress string, version string, platformOSName string) errorx.Error {
 	var url = "/bulk/registration/device/information/"
 
 	type deviceInfoRequest struct {
@@ -82,17 +105,17 @@ func (thisRef autoRegistration) SendDeviceInfo(registrationKey string, hardwareI
 	}
 
 	if resp.Status != apiContracts.API_ERROR_CODE_STATUS_TRUE {
-		if strings.Contains(resp.Reason, apiContracts.API_ERROR_CODE_REASON_NO_MATCHING_BULK_PROJECT) {
-			return apiContracts.ErrAutoreg_NoMatchingRegInfo
-		}
-
-		return errorx.New(apiContracts.ErrAutoReg_Generic, resp.Reason)
+		return apiContracts.ParseAPIError(resp.Reason).ToError(autoRegistrationAPIErrors, apiContracts.ErrAutoReg_Generic, nil)
 	}
 
 	return nil
 }
 
-func (thisRef autoRegistration) GetProductTemplate(registrationKey string, deviceUniquID string) ([]string, bool, string, errorx.Error) {
+func (thisRef autoRegistration) GetProductTemplate(registrationKey string, deviceUniquID string) (_ []string, _ bool, _ string, errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "AutoRegistration.GetProductTemplate")
+	defer op.end(&errx)
+
 	var url = fmt.Sprintf("/bulk/registration/device/friendly/configuration/%s/%s/", registrationKey, deviceUniquID)
 
 	raw, errx := thisRef.apiClient.Get(url)
@@ -122,7 +145,10 @@ func (thisRef autoRegistration) GetProductTemplate(registrationKey string, devic
 	return strings.Split(resp.Projects, ","), false, "", nil
 }
 
-func (thisRef autoRegistration) GetServiceConfigFromTemplateID(serviceID string, hardwareID string) (apiContracts.ServiceConfigResponse, errorx.Error) {
+func (thisRef autoRegistration) GetServiceConfigFromTemplateID(serviceID string, hardwareID string) (_ apiContracts.ServiceConfigResponse, errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "AutoRegistration.GetServiceConfigFromTemplateID")
+	defer op.end(&errx)
 
 	var url = fmt.Sprintf("/bulk/registration/configuration/%s/%s/", serviceID, hardwareID)
 
@@ -138,41 +164,82 @@ func (thisRef autoRegistration) GetServiceConfigFromTemplateID(serviceID string,
 		Enabled     string `json:"enabled"`
 		ProjectID   string `json:"project_id"`
 		Status      string `json:"status"`
+		Reason      string `json:"reason"`
 		Timestamp   string `json:"timestamp"`
 	}
 
 	var resp serviceConfigResponse
 	err := json.Unmarshal(raw, &resp)
 	if err != nil {
-		return apiContracts.ServiceConfigResponse{}, apiContracts.ErrAutoreg_CantPrepRequest
+		return apiContracts.ServiceConfigResponse{}, apiContracts.ErrAutoreg_CantReadResponse
 	}
 
-	port, err := strconv.Atoi(resp.ContentPort)
-	if err != nil {
-		port = 65535
+	if resp.Status != apiContracts.API_ERROR_CODE_STATUS_TRUE {
+		unknown := apiContracts.NewError(apiContracts.ErrAutoReg_Generic, fmt.Sprintf("unexpected status %q", resp.Status))
+		return apiContracts.ServiceConfigResponse{}, apiContracts.ParseAPIError(resp.Reason).ToError(autoRegistrationAPIErrors, apiContracts.ErrAutoReg_Generic, unknown)
 	}
 
-	var serviceType int
-	t, err := strconv.Atoi(resp.ContentType)
-	if err != nil {
-		serviceType = apiContracts.BulkServiceID
-	} else {
-		serviceType = t
+	serviceType, err := strconv.Atoi(strings.TrimSpace(resp.ContentType))
+	if err != nil || serviceType < 0 {
+		return apiContracts.ServiceConfigResponse{}, apiContracts.ErrAutoreg_CantConvertType
+	}
+
+	config := apiContracts.ServiceConfigResponse{
+		Hostname:  resp.ContentIP,
+		Type:      serviceType,
+		Protocol:  apiContracts.GetServiceProtocol(serviceType),
+		Disabled:  resp.Enabled != "1",
+		ProjectID: resp.ProjectID,
+		Timestamp: resp.Timestamp,
 	}
 
+	config.Ports, err = parseServicePorts(resp.ContentPort, config.IsMultiPort())
 	if err != nil {
 		return apiContracts.ServiceConfigResponse{}, apiContracts.ErrAutoreg_CantConvertPort
 	}
+	if len(config.Ports) > 0 {
+		config.Port = config.Ports[0]
+	}
 
-	return apiContracts.ServiceConfigResponse{
-		Hostname: resp.ContentIP,
-		Port:     port,
-		Type:     serviceType,
-		Disabled: resp.Enabled != "1",
-	}, nil
+	return config, nil
 }
 
-func (thisRef autoRegistration) RegisterService(serviceID string, uniqueDeviceID string, registrationKey string) (apiContracts.ServiceCredentials, bool, errorx.Error) {
+// parseServicePorts reads a single port, or a comma separated list for multi-port services
+// which may also come without any port at all.
+func parseServicePorts(value string, multiPort bool) ([]int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		if multiPort {
+			return []int{}, nil
+		}
+		return nil, fmt.Errorf("missing port")
+	}
+
+	parts := strings.Split(value, ",")
+	if len(parts) > 1 && !multiPort {
+		return nil, fmt.Errorf("multiple ports for a single port service")
+	}
+
+	ports := []int{}
+	for _, part := range parts {
+		port, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		if port <= 0 || port > 65535 {
+			return nil, fmt.Errorf("port %d out of range", port)
+		}
+
+		ports = append(ports, port)
+	}
+
+	return ports, nil
+}
+
+func (thisRef autoRegistration) RegisterService(serviceID string, uniqueDeviceID string, registrationKey string) (_ apiContracts.ServiceCredentials, _ bool, errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "AutoRegistration.RegisterService")
+	defer op.end(&errx)
 
 	var url = "/bulk/registration/register"
 
@@ -212,7 +279,7 @@ func (thisRef autoRegistration) RegisterService(serviceID string, uniqueDeviceID
 	}
 
 	if resp.Status == apiContracts.API_ERROR_CODE_STATUS_FALSE {
-		return apiContracts.ServiceCredentials{}, false, errorx.New(apiContracts.ErrAPI_AutoReg_Generic, resp.Reason)
+		return apiContracts.ServiceCredentials{}, false, apiContracts.ParseAPIError(resp.Reason).ToError(autoRegistrationAPIErrors, apiContracts.ErrAPI_AutoReg_Generic, nil)
 	}
 	if resp.Status == apiContracts.API_ERROR_CODE_STATUS_PENDING {
 		return apiContracts.ServiceCredentials{}, true, nil
@@ -223,3 +290,351 @@ func (thisRef autoRegistration) RegisterService(serviceID string, uniqueDeviceID
 		Secret: resp.Secret,
 	}, false, nil
 }
+
+// AutoRegisterIfNeeded runs the full bulk registration flow: it sends the device info,
+// fetches the product template, resolves the service config of every template ID and
+// registers each one, polling with backoff while the API reports it as pending.
+// With a state store, template IDs registered by a previous run are never registered again.
+func (thisRef autoRegistration) AutoRegisterIfNeeded(request apiContracts.AutoRegistrationRequest) (_ []apiContracts.Service, errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "AutoRegistration.AutoRegisterIfNeeded")
+	defer op.end(&errx)
+
+	if isNullOrEmpty(request.RegistrationKey) {
+		return nil, apiContracts.ErrAutoreg_BICEmpty
+	}
+
+	maxAttempts := request.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = apiContracts.DEFAULT_AUTOREG_MAX_ATTEMPTS
+	}
+
+	state, errx := thisRef.loadState(request)
+	if errx != nil {
+		return nil, errx
+	}
+
+	hardwareID := request.HardwareID
+	if !isNullOrEmpty(state.ResolvedHardwareID) {
+		hardwareID = state.ResolvedHardwareID
+	}
+
+	// 1. send device info and fetch the templates, a "reset" status hands out a new
+	//    hardware ID and the device info has to be sent again for it
+	var templateIDs []string
+	for attempt := 1; ; attempt++ {
+		if attempt > maxAttempts {
+			return nil, apiContracts.ErrAutoreg_MaxAttempts
+		}
+		if attempt > 1 {
+			op.retry()
+		}
+
+		errx := thisRef.SendDeviceInfo(request.RegistrationKey, hardwareID, request.CPUID, request.MACAddress, request.Version, request.PlatformOSName)
+		if errx != nil {
+			return nil, errx
+		}
+
+		ids, reset, resetHardwareID, errx := thisRef.GetProductTemplate(request.RegistrationKey, hardwareID)
+		if errx != nil {
+			return nil, errx
+		}
+
+		if !reset {
+			templateIDs = ids
+			break
+		}
+
+		if !isNullOrEmpty(resetHardwareID) && resetHardwareID != hardwareID {
+			// credentials registered for the previous hardware ID are no longer valid
+			hardwareID = resetHardwareID
+			state = apiContracts.NewAutoRegistrationState(request.RegistrationKey, request.HardwareID)
+			state.ResolvedHardwareID = resetHardwareID
+		}
+	}
+
+	state.Pending = []string{}
+	for _, templateID := range templateIDs {
+		templateID = strings.TrimSpace(templateID)
+		if templateID == "" {
+			continue
+		}
+		if _, ok := state.Registered[templateID]; !ok {
+			state.Pending = append(state.Pending, templateID)
+		}
+	}
+	if errx := thisRef.saveState(state); errx != nil {
+		return nil, errx
+	}
+
+	// 2. resolve and register every template
+	services := []apiContracts.Service{}
+	for _, templateID := range templateIDs {
+		templateID = strings.TrimSpace(templateID)
+		if templateID == "" {
+			continue
+		}
+
+		config, errx := thisRef.GetServiceConfigFromTemplateID(templateID, hardwareID)
+		if errx != nil {
+			return nil, errx
+		}
+
+		credentials, registered := state.Registered[templateID]
+		if !registered {
+			credentials, errx = thisRef.registerServiceUntilDone(op, templateID, hardwareID, request, maxAttempts)
+			if errx != nil {
+				return nil, errx
+			}
+
+			state.Registered[templateID] = credentials
+			state.Pending = removeString(state.Pending, templateID)
+			if errx := thisRef.saveState(state); errx != nil {
+				return nil, errx
+			}
+		}
+
+		services = append(services, newServiceFromAutoRegistration(templateID, hardwareID, config, credentials))
+	}
+
+	return services, nil
+}
+
+func (thisRef autoRegistration) loadState(request apiContracts.AutoRegistrationRequest) (apiContracts.AutoRegistrationState, errorx.Error) {
+	fresh := apiContracts.NewAutoRegistrationState(request.RegistrationKey, request.HardwareID)
+	if thisRef.stateStore == nil {
+		return fresh, nil
+	}
+
+	state, found, errx := thisRef.stateStore.Load()
+	if errx != nil {
+		return apiContracts.AutoRegistrationState{}, errx
+	}
+
+	// a state recorded for another key or device does not apply to this run
+	if !found || state.RegistrationKey != request.RegistrationKey || state.HardwareID != request.HardwareID {
+		return fresh, nil
+	}
+
+	return state, nil
+}
+
+func (thisRef autoRegistration) saveState(state apiContracts.AutoRegistrationState) errorx.Error {
+	if thisRef.stateStore == nil {
+		return nil
+	}
+
+	return thisRef.stateStore.Save(state)
+}
+
+func (thisRef autoRegistration) registerServiceUntilDone(op *operation, templateID string, hardwareID string, request apiContracts.AutoRegistrationRequest, maxAttempts int) (apiContracts.ServiceCredentials, errorx.Error) {
+	backoff := request.InitialBackoff
+	if backoff <= 0 {
+		backoff = apiContracts.DEFAULT_AUTOREG_INITIAL_BACKOFF
+	}
+
+	maxBackoff := request.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = apiContracts.DEFAULT_AUTOREG_MAX_BACKOFF
+	}
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		credentials, pending, errx := thisRef.RegisterService(templateID, hardwareID, request.RegistrationKey)
+		if errx != nil {
+			return apiContracts.ServiceCredentials{}, errx
+		}
+
+		if !pending {
+			return credentials, nil
+		}
+
+		if attempt == maxAttempts {
+			break
+		}
+
+		time.Sleep(backoff)
+		op.retry()
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	return apiContracts.ServiceCredentials{}, apiContracts.ErrAutoreg_MaxAttempts
+}
+
+func newServiceFromAutoRegistration(templateID string, hardwareID string, config apiContracts.ServiceConfigResponse, credentials apiContracts.ServiceCredentials) apiContracts.Service {
+	overload := 0
+	if config.Type == apiContracts.BulkServiceID {
+		overload = apiContracts.MultiPortServiceID
+	}
+
+	return apiContracts.Service{
+		CreatedTimestamp: time.Now().Unix(),
+		Disabled:         config.Disabled,
+		HardwareID:       hardwareID,
+		Hostname:         config.Hostname,
+		Overload:         overload,
+		Port:             config.Port,
+		Secret:           credentials.Secret,
+		Type:             config.Type,
+		UID:              credentials.UID,
+		TemplateID:       templateID,
+	}
+}
+
+func (thisRef autoRegistration) ReportComponentVersions(uid string, components []apiContracts.ComponentVersion) (errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "AutoRegistration.ReportComponentVersions")
+	defer op.end(&errx)
+
+	var url = "/device/component/version"
+
+	type componentVersionRequest struct {
+		UID        string                          `json:"deviceaddress"`
+		Components []apiContracts.ComponentVersion `json:"components"`
+	}
+
+	body, err := json.Marshal(componentVersionRequest{
+		UID:        uid,
+		Components: components,
+	})
+	if err != nil {
+		return apiContracts.ErrAutoreg_CantPrepRequest
+	}
+
+	raw, errx := thisRef.apiClient.Post(url, body)
+	if errx != nil {
+		return apiContracts.ErrAutoreg_CantSendRequest
+	}
+
+	type componentVersionResponse struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	}
+
+	var resp componentVersionResponse
+	err = json.Unmarshal(raw, &resp)
+	if err != nil {
+		return apiContracts.ErrAutoreg_CantReadResponse
+	}
+
+	if resp.Status != apiContracts.API_ERROR_CODE_STATUS_TRUE {
+		return apiContracts.ParseAPIError(resp.Reason).ToError(autoRegistrationAPIErrors, apiContracts.ErrAutoReg_Generic, nil)
+	}
+
+	return nil
+}
+
+func (thisRef autoRegistration) GetProjectEnablement(registrationKey string, hardwareID string) (_ apiContracts.ProjectEnablement, errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "AutoRegistration.GetProjectEnablement")
+	defer op.end(&errx)
+
+	var url = fmt.Sprintf("/device/enablement/%s/%s/", registrationKey, hardwareID)
+
+	raw, errx := thisRef.apiClient.Get(url)
+	if errx != nil {
+		return apiContracts.ProjectEnablement{}, errx
+	}
+
+	type enablementResponse struct {
+		Status    string `json:"status"`
+		Reason    string `json:"reason"`
+		ProjectID string `json:"project_id"`
+		Enabled   string `json:"enabled"`
+	}
+
+	var resp enablementResponse
+	err := json.Unmarshal(raw, &resp)
+	if err != nil {
+		return apiContracts.ProjectEnablement{}, apiContracts.ErrAutoreg_CantReadResponse
+	}
+
+	if resp.Status != apiContracts.API_ERROR_CODE_STATUS_TRUE {
+		return apiContracts.ProjectEnablement{}, apiContracts.ParseAPIError(resp.Reason).ToError(autoRegistrationAPIErrors, apiContracts.ErrAutoReg_Generic, nil)
+	}
+
+	return apiContracts.ProjectEnablement{
+		ProjectID: resp.ProjectID,
+		Enabled:   resp.Enabled == "1" || resp.Enabled == apiContracts.API_ERROR_CODE_STATUS_TRUE,
+	}, nil
+}
+
+func (thisRef autoRegistration) GetProvisioning(registrationKey string, hardwareID string) (_ apiContracts.ProvisioningInfo, errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "AutoRegistration.GetProvisioning")
+	defer op.end(&errx)
+
+	var url = fmt.Sprintf("/project/provisioning/%s/%s/", registrationKey, hardwareID)
+
+	raw, errx := thisRef.apiClient.Get(url)
+	if errx != nil {
+		return apiContracts.ProvisioningInfo{}, errx
+	}
+
+	type provisioningResponse struct {
+		Status    string `json:"status"`
+		Reason    string `json:"reason"`
+		ProjectID string `json:"project_id"`
+		Version   string `json:"version"`
+		FileName  string `json:"filename"`
+		Size      string `json:"size"`
+		Checksum  string `json:"sha256"`
+	}
+
+	var resp provisioningResponse
+	err := json.Unmarshal(raw, &resp)
+	if err != nil {
+		return apiContracts.ProvisioningInfo{}, apiContracts.ErrAutoreg_CantReadResponse
+	}
+
+	if resp.Status != apiContracts.API_ERROR_CODE_STATUS_TRUE {
+		return apiContracts.ProvisioningInfo{}, apiContracts.ParseAPIError(resp.Reason).ToError(autoRegistrationAPIErrors, apiContracts.ErrAutoReg_Generic, nil)
+	}
+
+	var size int64
+	if resp.Size != "" {
+		size, err = strconv.ParseInt(resp.Size, 10, 64)
+		if err != nil {
+			return apiContracts.ProvisioningInfo{}, apiContracts.ErrAutoreg_CantReadResponse
+		}
+	}
+
+	return apiContracts.ProvisioningInfo{
+		ProjectID: resp.ProjectID,
+		Version:   resp.Version,
+		FileName:  resp.FileName,
+		Size:      size,
+		Checksum:  strings.ToLower(resp.Checksum),
+	}, nil
+}
+
+// DownloadProvisioning streams the provisioning bundle into `writer`. When `checksum` is
+// set the SHA-256 of the streamed data must match it, otherwise whatever was written
+// must be discarded by the caller. The client must also be a `Downloader`.
+func (thisRef autoRegistration) DownloadProvisioning(registrationKey string, hardwareID string, checksum string, writer io.Writer) (_ int64, errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "AutoRegistration.DownloadProvisioning")
+	defer op.end(&errx)
+
+	var url = fmt.Sprintf("/project/provisioning/download/%s/%s/", registrationKey, hardwareID)
+
+	downloader, ok := thisRef.apiClient.(Downloader)
+	if !ok {
+		return 0, apiContracts.ErrAutoreg_CantDownload
+	}
+
+	hash := sha256.New()
+	written, errx := downloader.Download(url, io.MultiWriter(writer, hash))
+	if errx != nil {
+		return written, apiContracts.NewErrorFromErr(apiContracts.ErrAutoreg_CantDownload.Code(), errx)
+	}
+
+	if checksum != "" && !strings.EqualFold(hex.EncodeToString(hash.Sum(nil)), checksum) {
+		return written, apiContracts.ErrAutoreg_ChecksumMismatch
+	}
+
+	return written, nil
+}
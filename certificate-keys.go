@@ -0,0 +1,71 @@
+package api
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"net"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+func generatePrivateKey(keyType apiContracts.CertificateKeyType) (crypto.Signer, error) {
+	switch keyType {
+	case apiContracts.CertificateKeyECDSAP256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case apiContracts.CertificateKeyECDSAP384:
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case apiContracts.CertificateKeyRSA2048:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case apiContracts.CertificateKeyRSA4096:
+		return rsa.GenerateKey(rand.Reader, 4096)
+	default:
+		return nil, fmt.Errorf("unknown key type %d", keyType)
+	}
+}
+
+func encodePrivateKeyToPEM(key crypto.Signer) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// createCSR builds the signing request for `request`, the common name is the
+// service name when set, the service ID otherwise.
+func createCSR(request apiContracts.CertificateRequest, key crypto.Signer) (string, error) {
+	commonName := request.Name
+	if commonName == "" {
+		commonName = request.ServiceID
+	}
+
+	template := &x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName:   commonName,
+			SerialNumber: request.MachineID,
+		},
+	}
+
+	if request.IP != "" {
+		if ip := net.ParseIP(request.IP); ip != nil {
+			template.IPAddresses = []net.IP{ip}
+		} else {
+			template.DNSNames = []string{request.IP}
+		}
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		return "", err
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})), nil
+}
@@ -0,0 +1,243 @@
+package api
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+// CertificateRenewer keeps a certificate from `CertificateClient` on disk and renews it
+// once the configured fraction of its lifetime has passed. Plug `GetCertificate` into a
+// `tls.Config` to always serve the latest certificate without restarting the server. Once
+// the renewals failed until it expired it is no longer served and `OnError` is told.
+type CertificateRenewer interface {
+	Start() errorx.Error
+	Stop()
+	RenewNow() errorx.Error
+
+	Current() (apiContracts.ParsedCertificate, bool)
+	GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error)
+	TLSConfig() *tls.Config
+}
+
+func NewCertificateRenewer(certificateClient CertificateClient, options apiContracts.CertificateRenewerOptions) CertificateRenewer {
+	if options.RenewAtFraction <= 0 || options.RenewAtFraction >= 1 {
+		options.RenewAtFraction = apiContracts.DEFAULT_CERTIFICATE_RENEW_AT_FRACTION
+	}
+	if options.RetryInterval <= 0 {
+		options.RetryInterval = apiContracts.DEFAULT_CERTIFICATE_RENEW_RETRY_INTERVAL
+	}
+
+	return &certificateRenewer{
+		certificateClient: certificateClient,
+		options:           options,
+	}
+}
+
+type certificateRenewer struct {
+	certificateClient CertificateClient
+	options           apiContracts.CertificateRenewerOptions
+
+	mutex   sync.RWMutex
+	current *apiContracts.ParsedCertificate
+
+	stopMutex sync.Mutex
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// Start loads the stored certificate, renews it right away if it is missing or due, and
+// then keeps renewing it in the background until `Stop` is called.
+func (thisRef *certificateRenewer) Start() errorx.Error {
+	thisRef.stopMutex.Lock()
+	defer thisRef.stopMutex.Unlock()
+
+	if thisRef.stop != nil {
+		return nil
+	}
+
+	if stored, errx := thisRef.load(); errx == nil {
+		thisRef.swap(stored)
+	}
+
+	if current, ok := thisRef.Current(); !ok || !time.Now().Before(thisRef.renewAt(current)) {
+		if errx := thisRef.RenewNow(); errx != nil {
+			// an older certificate that is still valid keeps being served
+			if current, ok := thisRef.Current(); !ok || !current.IsValidAt(time.Now()) {
+				return errx
+			}
+			thisRef.reportError(errx)
+		}
+	}
+
+	thisRef.stop = make(chan struct{})
+	thisRef.done = make(chan struct{})
+	go thisRef.run(thisRef.stop, thisRef.done)
+
+	return nil
+}
+
+func (thisRef *certificateRenewer) Stop() {
+	thisRef.stopMutex.Lock()
+	defer thisRef.stopMutex.Unlock()
+
+	if thisRef.stop == nil {
+		return
+	}
+
+	close(thisRef.stop)
+	<-thisRef.done
+
+	thisRef.stop = nil
+	thisRef.done = nil
+}
+
+// RenewNow asks for a new certificate, stores it and swaps it in.
+func (thisRef *certificateRenewer) RenewNow() errorx.Error {
+	var response *apiContracts.CertificateResponse
+	var errx errorx.Error
+	if thisRef.options.UseLocalKey {
+		response, errx = thisRef.certificateClient.GenerateWithLocalKey(thisRef.options.Request, thisRef.options.KeyType)
+	} else {
+		response, errx = thisRef.certificateClient.Generate(thisRef.options.Request)
+	}
+	if errx != nil {
+		return errx
+	}
+
+	parsed, errx := response.Parse()
+	if errx != nil {
+		return errx
+	}
+
+	if errx := thisRef.store(*response); errx != nil {
+		return errx
+	}
+
+	thisRef.swap(parsed)
+
+	if thisRef.options.OnRenewed != nil {
+		thisRef.options.OnRenewed(parsed)
+	}
+
+	return nil
+}
+
+func (thisRef *certificateRenewer) Current() (apiContracts.ParsedCertificate, bool) {
+	thisRef.mutex.RLock()
+	defer thisRef.mutex.RUnlock()
+
+	if thisRef.current == nil {
+		return apiContracts.ParsedCertificate{}, false
+	}
+
+	return *thisRef.current, true
+}
+
+func (thisRef *certificateRenewer) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	current, ok := thisRef.Current()
+	if !ok {
+		return nil, apiContracts.ErrAPI_CertClient_NoCertificate
+	}
+
+	// the renewals kept failing, the clients would refuse it anyway
+	if !current.IsValidAt(time.Now()) {
+		return nil, apiContracts.ErrAPI_CertClient_Expired
+	}
+
+	return &current.TLSCertificate, nil
+}
+
+func (thisRef *certificateRenewer) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: thisRef.GetCertificate,
+	}
+}
+
+func (thisRef *certificateRenewer) run(stop chan struct{}, done chan struct{}) {
+	defer close(done)
+
+	for {
+		// a certificate that is already due right after renewing would otherwise spin
+		wait := thisRef.options.RetryInterval
+		if current, ok := thisRef.Current(); ok {
+			if untilRenew := time.Until(thisRef.renewAt(current)); untilRenew > 0 {
+				wait = untilRenew
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// a failed renewal leaves the certificate due, the next wait is the RetryInterval
+		if errx := thisRef.RenewNow(); errx != nil {
+			thisRef.reportError(errx)
+
+			if current, ok := thisRef.Current(); ok && !current.IsValidAt(time.Now()) {
+				thisRef.reportError(apiContracts.ErrAPI_CertClient_Expired)
+			}
+		}
+	}
+}
+
+func (thisRef *certificateRenewer) renewAt(certificate apiContracts.ParsedCertificate) time.Time {
+	lifetime := float64(certificate.Lifetime())
+	return certificate.NotBefore.Add(time.Duration(lifetime * thisRef.options.RenewAtFraction))
+}
+
+func (thisRef *certificateRenewer) swap(certificate apiContracts.ParsedCertificate) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	thisRef.current = &certificate
+}
+
+func (thisRef *certificateRenewer) reportError(errx errorx.Error) {
+	if thisRef.options.OnError != nil {
+		thisRef.options.OnError(errx)
+	}
+}
+
+func (thisRef *certificateRenewer) load() (apiContracts.ParsedCertificate, errorx.Error) {
+	certificate, err := ioutil.ReadFile(thisRef.options.CertificatePath)
+	if err != nil {
+		return apiContracts.ParsedCertificate{}, apiContracts.NewErrorFromErr(apiContracts.ErrAPI_CertClient_NoCertificate.Code(), err)
+	}
+
+	key, err := ioutil.ReadFile(thisRef.options.KeyPath)
+	if err != nil {
+		return apiContracts.ParsedCertificate{}, apiContracts.NewErrorFromErr(apiContracts.ErrAPI_CertClient_NoCertificate.Code(), err)
+	}
+
+	return apiContracts.CertificateResponse{
+		Certificate: string(certificate),
+		Key:         string(key),
+	}.Parse()
+}
+
+func (thisRef *certificateRenewer) store(response apiContracts.CertificateResponse) errorx.Error {
+	if thisRef.options.CertificatePath == "" || thisRef.options.KeyPath == "" {
+		return nil
+	}
+
+	// the key goes first, a crash in between leaves a pair that fails to parse and gets renewed
+	if err := writeFileAtomic(thisRef.options.KeyPath, []byte(response.Key), 0600); err != nil {
+		return apiContracts.NewErrorFromErr(apiContracts.ErrAPI_CertClient_CantStore.Code(), err)
+	}
+
+	if err := writeFileAtomic(thisRef.options.CertificatePath, []byte(response.Certificate), 0644); err != nil {
+		return apiContracts.NewErrorFromErr(apiContracts.ErrAPI_CertClient_CantStore.Code(), err)
+	}
+
+	return nil
+}
@@ -11,38 +11,66 @@ import (
 
 type CertificateClient interface {
 	Generate(request apiContracts.CertificateRequest) (*apiContracts.CertificateResponse, errorx.Error)
+	GenerateWithLocalKey(request apiContracts.CertificateRequest, keyType apiContracts.CertificateKeyType) (*apiContracts.CertificateResponse, errorx.Error)
 }
 
 type certificateClient struct {
 	apiURL     string
-	apiToken   string
+	tokens     TokenSource
 	apiTimeout time.Duration
 	userAgent  string
+	transport  http.RoundTripper
+
+	instrumented
 }
 
 func NewCertificateClient(apiURL string, apiToken string, apiTimeout time.Duration, userAgent string) CertificateClient {
+	return NewCertificateClientWithTransport(apiURL, apiToken, apiTimeout, userAgent, nil)
+}
+
+func NewCertificateClientWithTransport(apiURL string, apiToken string, apiTimeout time.Duration, userAgent string, transport http.RoundTripper) CertificateClient {
+	return NewCertificateClientWithTokenSource(apiURL, staticToken(apiToken), apiTimeout, userAgent, transport)
+}
+
+func NewCertificateClientWithTokenSource(apiURL string, tokens TokenSource, apiTimeout time.Duration, userAgent string, transport http.RoundTripper) CertificateClient {
+	return newCertificateClient(apiURL, tokens, apiTimeout, userAgent, transport, instrumented{})
+}
+
+func newCertificateClient(apiURL string, tokens TokenSource, apiTimeout time.Duration, userAgent string, transport http.RoundTripper, base instrumented) *certificateClient {
 	return &certificateClient{
 		apiURL:     apiURL,
-		apiToken:   apiToken,
+		tokens:     tokens,
 		apiTimeout: apiTimeout,
 		userAgent:  userAgent,
+		transport:  transport,
+
+		instrumented: base,
 	}
 }
 
-func (thisRef certificateClient) Generate(request apiContracts.CertificateRequest) (*apiContracts.CertificateResponse, errorx.Error) {
+func (thisRef certificateClient) Generate(request apiContracts.CertificateRequest) (_ *apiContracts.CertificateResponse, errx errorx.Error) {
+	var op *operation
+	op, thisRef.instrumented = thisRef.startOperation("Certificate.Generate")
+	defer op.end(&errx)
+
 	payload, err := json.Marshal(request)
 	if err != nil {
-		return nil, apiContracts.ErrAPI_RestoreClient_CantPrepRequest
+		return nil, apiContracts.ErrAPI_CertClient_CantPrepRequest
+	}
+
+	token, errx := thisRef.tokens.Token()
+	if errx != nil {
+		return nil, errx
 	}
 
 	headers := map[string]string{
 		"User-Agent": thisRef.userAgent,
-		"token":      thisRef.apiToken,
+		"token":      token,
 	}
 
-	httpResponse, data, err := doHTTPRequest(http.MethodPost, headers, thisRef.apiURL, payload, thisRef.apiTimeout)
+	httpResponse, data, err := doHTTPRequestWithSession(thisRef.context(), thisRef.tokens, thisRef.transport, http.MethodPost, headers, thisRef.apiURL, payload, thisRef.apiTimeout)
 	if err != nil {
-		return nil, errorx.NewFromErr(apiContracts.ErrAPI_CertClient_Generic, err)
+		return nil, apiContracts.NewErrorFromErr(apiContracts.ErrAPI_CertClient_CantSendRequest.Code(), err)
 	}
 
 	if httpResponse.StatusCode != 200 {
@@ -50,7 +78,7 @@ func (thisRef certificateClient) Generate(request apiContracts.CertificateReques
 		case 401:
 			return nil, apiContracts.ErrAPI_CertClient_TokenNotSpecified
 		default:
-			return nil, errorx.New(apiContracts.ErrAPI_CertClient_Generic, httpResponse.Status)
+			return nil, apiContracts.NewReasonError(apiContracts.ErrAPI_CertClient_Generic, httpResponse.Status)
 		}
 	}
 
@@ -58,8 +86,45 @@ func (thisRef certificateClient) Generate(request apiContracts.CertificateReques
 	response := apiContracts.CertificateResponse{}
 	err = json.Unmarshal(data, &response)
 	if err != nil {
-		return nil, apiContracts.ErrAPI_Client_Error
+		return nil, apiContracts.ErrAPI_CertClient_CantReadResponse
 	}
 
 	return &response, nil
 }
+
+// GenerateWithLocalKey creates the key pair on the device and only sends a CSR, the
+// private key never leaves the device. The returned `Key` is the local PKCS #8 PEM key.
+func (thisRef certificateClient) GenerateWithLocalKey(request apiContracts.CertificateRequest, keyType apiContracts.CertificateKeyType) (_ *apiContracts.CertificateResponse, errx errorx.Error) {
+	var op *operation
+	op, thisRef.instrumented = thisRef.startOperation("Certificate.GenerateWithLocalKey")
+	defer op.end(&errx)
+
+	key, err := generatePrivateKey(keyType)
+	if err != nil {
+		return nil, apiContracts.NewErrorFromErr(apiContracts.ErrAPI_CertClient_CantGenerateKey.Code(), err)
+	}
+
+	keyAsPEM, err := encodePrivateKeyToPEM(key)
+	if err != nil {
+		return nil, apiContracts.NewErrorFromErr(apiContracts.ErrAPI_CertClient_CantGenerateKey.Code(), err)
+	}
+
+	request.CSR, err = createCSR(request, key)
+	if err != nil {
+		return nil, apiContracts.NewErrorFromErr(apiContracts.ErrAPI_CertClient_CantCreateCSR.Code(), err)
+	}
+
+	response, errx := thisRef.Generate(request)
+	if errx != nil {
+		return nil, errx
+	}
+
+	response.Key = keyAsPEM
+
+	// make sure the server signed our key and not something else
+	if _, errx := response.Parse(); errx != nil {
+		return nil, errx
+	}
+
+	return response, nil
+}
@@ -18,6 +18,9 @@ var cachedApplicationTypesMutex sync.Mutex
 
 const cachedApplicationTypesExpireDuration = 10 * time.Hour
 
+// how many devices `GetDevices` asks for at once
+const graphQLDevicesPageSize = 500
+
 type GraphQLClient interface {
 	GetApplicationTypes() ([]apiContracts.ApplicationType, errorx.Error)
 	GetApplicationTypesIgnoreCache() ([]apiContracts.ApplicationType, errorx.Error)
@@ -25,25 +28,51 @@ type GraphQLClient interface {
 	GetApplicationType(serviceID string) (int, errorx.Error)
 	GetDeviceAndServiceNames(deviceID string) (apiContracts.DefinedDevice, errorx.Error)
 	GetServiceNamesByIDs(serviceIDs []string) ([]apiContracts.DefinedService, errorx.Error)
+
+	// GetDevices lists every device of the account with its services and their open proxy
+	// connections, a page at a time.
+	GetDevices() ([]apiContracts.ListedDevice, errorx.Error)
 }
 
 func NewGraphQLClient(apiURL string, apiToken string, apiTimeout time.Duration, userAgent string) GraphQLClient {
+	return NewGraphQLClientWithTransport(apiURL, apiToken, apiTimeout, userAgent, nil)
+}
+
+func NewGraphQLClientWithTransport(apiURL string, apiToken string, apiTimeout time.Duration, userAgent string, transport http.RoundTripper) GraphQLClient {
+	return NewGraphQLClientWithTokenSource(apiURL, staticToken(apiToken), apiTimeout, userAgent, transport)
+}
+
+func NewGraphQLClientWithTokenSource(apiURL string, tokens TokenSource, apiTimeout time.Duration, userAgent string, transport http.RoundTripper) GraphQLClient {
+	return newGraphQLClient(apiURL, tokens, apiTimeout, userAgent, transport, instrumented{})
+}
+
+func newGraphQLClient(apiURL string, tokens TokenSource, apiTimeout time.Duration, userAgent string, transport http.RoundTripper, base instrumented) *graphQLClient {
 	return &graphQLClient{
 		apiURL:     apiURL,
-		apiToken:   apiToken,
+		tokens:     tokens,
 		apiTimeout: apiTimeout,
 		userAgent:  userAgent,
+		transport:  transport,
+
+		instrumented: base,
 	}
 }
 
 type graphQLClient struct {
 	apiURL     string
-	apiToken   string
+	tokens     TokenSource
 	apiTimeout time.Duration
 	userAgent  string
+	transport  http.RoundTripper
+
+	instrumented
 }
 
-func (thisRef graphQLClient) GetApplicationTypes() ([]apiContracts.ApplicationType, errorx.Error) {
+func (thisRef graphQLClient) GetApplicationTypes() (_ []apiContracts.ApplicationType, errx errorx.Error) {
+	var op *operation
+	op, thisRef.instrumented = thisRef.startOperation("GraphQL.GetApplicationTypes")
+	defer op.end(&errx)
+
 	cachedApplicationTypesMutex.Lock()
 
 	if !cachedApplicationTypesCreateTime.IsZero() && time.Since(cachedApplicationTypesCreateTime) < cachedApplicationTypesExpireDuration {
@@ -55,7 +84,11 @@ func (thisRef graphQLClient) GetApplicationTypes() ([]apiContracts.ApplicationTy
 	return thisRef.GetApplicationTypesIgnoreCache()
 }
 
-func (thisRef graphQLClient) GetApplicationTypesIgnoreCache() ([]apiContracts.ApplicationType, errorx.Error) {
+func (thisRef graphQLClient) GetApplicationTypesIgnoreCache() (_ []apiContracts.ApplicationType, errx errorx.Error) {
+	var op *operation
+	op, thisRef.instrumented = thisRef.startOperation("GraphQL.GetApplicationTypesIgnoreCache")
+	defer op.end(&errx)
+
 	// 1. run
 	raw, err := thisRef.prepAndDoHTTPRequest(`{
 		applicationTypes {
@@ -98,7 +131,11 @@ func (thisRef graphQLClient) GetApplicationTypesIgnoreCache() ([]apiContracts.Ap
 	return cachedApplicationTypes, nil
 }
 
-func (thisRef graphQLClient) GetApplicationType(serviceID string) (int, errorx.Error) {
+func (thisRef graphQLClient) GetApplicationType(serviceID string) (_ int, errx errorx.Error) {
+	var op *operation
+	op, thisRef.instrumented = thisRef.startOperation("GraphQL.GetApplicationType")
+	defer op.end(&errx)
+
 	serviceID = strings.TrimSpace(serviceID)
 	if len(serviceID) == 0 {
 		return apiContracts.InvalidApplicationType, nil
@@ -140,7 +177,11 @@ func (thisRef graphQLClient) GetApplicationType(serviceID string) (int, errorx.E
 	return apiContracts.InvalidApplicationType, nil
 }
 
-func (thisRef graphQLClient) GetDeviceAndServiceNames(deviceID string) (apiContracts.DefinedDevice, errorx.Error) {
+func (thisRef graphQLClient) GetDeviceAndServiceNames(deviceID string) (_ apiContracts.DefinedDevice, errx errorx.Error) {
+	var op *operation
+	op, thisRef.instrumented = thisRef.startOperation("GraphQL.GetDeviceAndServiceNames")
+	defer op.end(&errx)
+
 	deviceID = strings.TrimSpace(deviceID)
 	if len(deviceID) == 0 {
 		return apiContracts.DefinedDevice{}, nil
@@ -197,7 +238,11 @@ func (thisRef graphQLClient) GetDeviceAndServiceNames(deviceID string) (apiContr
 	return apiContracts.DefinedDevice{}, nil
 }
 
-func (thisRef graphQLClient) GetServiceNamesByIDs(serviceIDs []string) ([]apiContracts.DefinedService, errorx.Error) {
+func (thisRef graphQLClient) GetServiceNamesByIDs(serviceIDs []string) (_ []apiContracts.DefinedService, errx errorx.Error) {
+	var op *operation
+	op, thisRef.instrumented = thisRef.startOperation("GraphQL.GetServiceNamesByIDs")
+	defer op.end(&errx)
+
 	// 1. run
 	updatedServiceIDs := []string{}
 	for _, serviceID := range serviceIDs {
@@ -253,6 +298,113 @@ func (thisRef graphQLClient) GetServiceNamesByIDs(serviceIDs []string) ([]apiCon
 	return definedServices, nil
 }
 
+func (thisRef graphQLClient) GetDevices() (_ []apiContracts.ListedDevice, errx errorx.Error) {
+	var op *operation
+	op, thisRef.instrumented = thisRef.startOperation("GraphQL.GetDevices")
+	defer op.end(&errx)
+
+	type gqlService struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		State       string `json:"state"`
+		Application int    `json:"application"`
+		Sessions    []struct {
+			ID string `json:"id"`
+		} `json:"sessions"`
+	}
+
+	type gqlDevice struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		State string `json:"state"`
+		Owner struct {
+			Email string `json:"email"`
+		} `json:"owner"`
+		Services []gqlService `json:"services"`
+	}
+
+	type gqlReply struct {
+		Data struct {
+			Login struct {
+				Devices struct {
+					Total int         `json:"total"`
+					Items []gqlDevice `json:"items"`
+				} `json:"devices"`
+			} `json:"login"`
+		} `json:"data"`
+	}
+
+	devices := []apiContracts.ListedDevice{}
+	for {
+		// 1. run
+		raw, err := thisRef.prepAndDoHTTPRequest(fmt.Sprintf(`{
+			login {
+				devices(size: %d, from: %d) {
+					total
+					items {
+						id
+						name
+						state
+						owner {
+							email
+						}
+						services {
+							id
+							name
+							state
+							application
+							sessions {
+								id
+							}
+						}
+					}
+				}
+			}
+		}`, graphQLDevicesPageSize, len(devices)))
+		if err != nil {
+			return []apiContracts.ListedDevice{}, err
+		}
+
+		// 2. read
+		var response gqlReply
+		if err := json.Unmarshal(raw, &response); err != nil {
+			return []apiContracts.ListedDevice{}, apiContracts.ErrAPI_GQL_CantReadResponse
+		}
+
+		for _, item := range response.Data.Login.Devices.Items {
+			device := apiContracts.ListedDevice{
+				ID:       item.ID,
+				Name:     item.Name,
+				State:    item.State,
+				Owner:    item.Owner.Email,
+				Services: []apiContracts.ListedService{},
+			}
+
+			for _, service := range item.Services {
+				listedService := apiContracts.ListedService{
+					ID:              service.ID,
+					Name:            service.Name,
+					State:           service.State,
+					ApplicationType: service.Application,
+					Connections:     []string{},
+				}
+				for _, session := range service.Sessions {
+					listedService.Connections = append(listedService.Connections, session.ID)
+				}
+
+				device.Services = append(device.Services, listedService)
+			}
+
+			devices = append(devices, device)
+		}
+
+		// 3. return once the last page is in
+		if len(response.Data.Login.Devices.Items) < graphQLDevicesPageSize || len(devices) >= response.Data.Login.Devices.Total {
+			return devices, nil
+		}
+	}
+}
+
 func (thisRef graphQLClient) prepAndDoHTTPRequest(query string) ([]byte, errorx.Error) {
 	type gqlReuqest struct {
 		Query string `json:"query"`
@@ -264,12 +416,17 @@ func (thisRef graphQLClient) prepAndDoHTTPRequest(query string) ([]byte, errorx.
 		return []byte{}, apiContracts.ErrAPI_GQL_CantPrepRequest
 	}
 
+	token, errx := thisRef.tokens.Token()
+	if errx != nil {
+		return nil, errx
+	}
+
 	headers := map[string]string{
 		"User-Agent": thisRef.userAgent,
-		"token":      thisRef.apiToken,
+		"token":      token,
 	}
 
-	_, data, err := doHTTPRequest(http.MethodPost, headers, thisRef.apiURL, payload, thisRef.apiTimeout)
+	_, data, err := doHTTPRequestWithSession(thisRef.context(), thisRef.tokens, thisRef.transport, http.MethodPost, headers, thisRef.apiURL, payload, thisRef.apiTimeout)
 	if err != nil {
 		return nil, apiContracts.ErrAPI_GQL_Error
 	}
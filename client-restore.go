@@ -10,26 +10,50 @@ import (
 )
 
 type RestoreClient interface {
-	Restore(deviceID string, machineID string) ([]byte, errorx.Error)
+	Restore(deviceID string, machineID string) (apiContracts.RestoreConfig, errorx.Error)
 }
 
 type restoreClient struct {
 	apiURL     string
-	apiToken   string
+	tokens     TokenSource
 	apiTimeout time.Duration
 	userAgent  string
+	transport  http.RoundTripper
+
+	instrumented
 }
 
 func NewRestoreClient(apiURL string, apiToken string, apiTimeout time.Duration, userAgent string) RestoreClient {
+	return NewRestoreClientWithTransport(apiURL, apiToken, apiTimeout, userAgent, nil)
+}
+
+func NewRestoreClientWithTransport(apiURL string, apiToken string, apiTimeout time.Duration, userAgent string, transport http.RoundTripper) RestoreClient {
+	return NewRestoreClientWithTokenSource(apiURL, staticToken(apiToken), apiTimeout, userAgent, transport)
+}
+
+func NewRestoreClientWithTokenSource(apiURL string, tokens TokenSource, apiTimeout time.Duration, userAgent string, transport http.RoundTripper) RestoreClient {
+	return newRestoreClient(apiURL, tokens, apiTimeout, userAgent, transport, instrumented{})
+}
+
+func newRestoreClient(apiURL string, tokens TokenSource, apiTimeout time.Duration, userAgent string, transport http.RoundTripper, base instrumented) *restoreClient {
 	return &restoreClient{
 		apiURL:     apiURL,
-		apiToken:   apiToken,
+		tokens:     tokens,
 		apiTimeout: apiTimeout,
 		userAgent:  userAgent,
+		transport:  transport,
+
+		instrumented: base,
 	}
 }
 
-func (thisRef restoreClient) Restore(deviceID string, machineID string) ([]byte, errorx.Error) {
+// Restore fetches and validates the config of a previously registered device, the raw
+// config is kept in `RestoreConfig.Raw`.
+func (thisRef restoreClient) Restore(deviceID string, machineID string) (_ apiContracts.RestoreConfig, errx errorx.Error) {
+	var op *operation
+	op, thisRef.instrumented = thisRef.startOperation("Restore.Restore")
+	defer op.end(&errx)
+
 	type payloadT struct {
 		DeviceId  string `json:"deviceId"`
 		MachineId string `json:"machineId"`
@@ -41,31 +65,36 @@ func (thisRef restoreClient) Restore(deviceID string, machineID string) ([]byte,
 	}
 	payload, err := json.Marshal(request)
 	if err != nil {
-		return nil, apiContracts.ErrAPI_RestoreClient_CantPrepRequest
+		return apiContracts.RestoreConfig{}, apiContracts.ErrAPI_RestoreClient_CantPrepRequest
+	}
+
+	token, errx := thisRef.tokens.Token()
+	if errx != nil {
+		return apiContracts.RestoreConfig{}, errx
 	}
 
 	headers := map[string]string{
 		"User-Agent": thisRef.userAgent,
-		"token":      thisRef.apiToken,
+		"token":      token,
 	}
 
-	response, data, err := doHTTPRequest(http.MethodPost, headers, thisRef.apiURL, payload, thisRef.apiTimeout)
+	response, data, err := doHTTPRequestWithSession(thisRef.context(), thisRef.tokens, thisRef.transport, http.MethodPost, headers, thisRef.apiURL, payload, thisRef.apiTimeout)
 	if err != nil {
-		return nil, apiContracts.ErrAPI_Client_Error
+		return apiContracts.RestoreConfig{}, apiContracts.ErrAPI_RestoreClient_CantSendRequest
 	}
 
 	if response.StatusCode != 200 {
 		switch response.StatusCode {
 		case 400:
-			return nil, apiContracts.ErrAPI_RestoreClient_DeviceActive
+			return apiContracts.RestoreConfig{}, apiContracts.ErrAPI_RestoreClient_DeviceActive
 		case 401:
-			return nil, apiContracts.ErrAPI_RestoreClient_TokenNotSpecified
+			return apiContracts.RestoreConfig{}, apiContracts.ErrAPI_RestoreClient_TokenNotSpecified
 		case 403:
-			return nil, apiContracts.ErrAPI_RestoreClient_DeviceNotExists
+			return apiContracts.RestoreConfig{}, apiContracts.ErrAPI_RestoreClient_DeviceNotExists
 		default:
-			return nil, errorx.New(apiContracts.ErrAPI_RestoreClient_Generic, response.Status)
+			return apiContracts.RestoreConfig{}, apiContracts.NewReasonError(apiContracts.ErrAPI_RestoreClient_Generic, response.Status)
 		}
 	}
 
-	return data, nil
+	return apiContracts.ParseRestoreConfig(data)
 }
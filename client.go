@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
@@ -31,12 +32,37 @@ type Client interface {
 	Get(endpointURL string) ([]byte, errorx.Error)
 }
 
+// Downloader is implemented by the clients of `NewClient`, it is kept out of Client so other
+// Client implementations don't have to stream. `AutoRegistration.DownloadProvisioning` needs it.
+type Downloader interface {
+	Download(endpointURL string, writer io.Writer) (int64, errorx.Error)
+}
+
 func NewClient(apiURL string, apiKey string, apiTimeout time.Duration, userAgent string) Client {
+	return NewClientWithTransport(apiURL, apiKey, apiTimeout, userAgent, nil)
+}
+
+// NewClientWithTransport sends every request through `transport`, http.DefaultTransport when nil.
+func NewClientWithTransport(apiURL string, apiKey string, apiTimeout time.Duration, userAgent string, transport http.RoundTripper) Client {
+	return NewClientWithTokenSource(apiURL, apiKey, apiTimeout, userAgent, nil, transport)
+}
+
+// NewClientWithTokenSource sends the token of `tokens` instead of the one of the last auth hash
+// login, the cached one is still used when `tokens` is nil.
+func NewClientWithTokenSource(apiURL string, apiKey string, apiTimeout time.Duration, userAgent string, tokens TokenSource, transport http.RoundTripper) Client {
+	return newClient(apiURL, apiKey, apiTimeout, userAgent, tokens, transport, instrumented{})
+}
+
+func newClient(apiURL string, apiKey string, apiTimeout time.Duration, userAgent string, tokens TokenSource, transport http.RoundTripper, base instrumented) *client {
 	return &client{
 		apiURL:     apiURL,
 		apiKey:     apiKey,
 		apiTimeout: apiTimeout,
 		userAgent:  userAgent,
+		tokens:     tokens,
+		transport:  transport,
+
+		instrumented: base,
 	}
 }
 
@@ -45,6 +71,10 @@ type client struct {
 	apiKey     string
 	apiTimeout time.Duration
 	userAgent  string
+	tokens     TokenSource
+	transport  http.RoundTripper
+
+	instrumented
 }
 
 func (thisRef client) CanConnect(onlineCheckEndpoint string, onlineCheckEndpointReply string) bool {
@@ -100,16 +130,7 @@ func (thisRef client) LoginWithPassword(username string, password string) (apiCo
 			}
 		}
 
-		if strings.Contains(response.Reason, apiContracts.API_ERROR_CODE_REASON_USER_OR_PASSWORD_INVALID) {
-			return apiContracts.Authentication{}, apiContracts.ErrAPI_Auth_PasswordInvalid
-		}
-		if strings.Contains(response.Reason, apiContracts.API_ERROR_CODE_REASON_MISSING_USER) {
-			return apiContracts.Authentication{}, apiContracts.ErrAPI_Auth_NoSuchUser
-		}
-		if !(len(strings.TrimSpace(response.Reason)) <= 0) {
-			return apiContracts.Authentication{}, errorx.New(apiContracts.ErrAPI_Auth_Generic, response.Reason)
-		}
-		return apiContracts.Authentication{}, apiContracts.ErrAPI_Auth_Unknown
+		return apiContracts.Authentication{}, apiContracts.ParseAPIError(response.Reason).ToError(loginPasswordAPIErrors, apiContracts.ErrAPI_Auth_Generic, apiContracts.ErrAPI_Auth_Unknown)
 	}
 
 	if response.ServiceAuthHash == "" {
@@ -137,6 +158,22 @@ func (thisRef client) LoginWithAuthHash(username string, authHash string) (apiCo
 }
 
 func (thisRef client) LoginWithAuthHashIgnoreCache(username string, authHash string) (apiContracts.Authentication, errorx.Error) {
+	authentication, errx := thisRef.loginWithAuthHash(username, authHash)
+	if errx != nil {
+		return apiContracts.Authentication{}, errx
+	}
+
+	cachedAuthResponseMutex.Lock()
+	defer cachedAuthResponseMutex.Unlock()
+
+	cachedAuthResponse = authentication
+	cachedAuthResponseCreateTime = time.Now()
+	return cachedAuthResponse, nil
+}
+
+// loginWithAuthHash logs in without touching the cached auth response, for the sessions that
+// keep their own.
+func (thisRef client) loginWithAuthHash(username string, authHash string) (apiContracts.Authentication, errorx.Error) {
 	type requestBody struct {
 		AuthHash string `json:"authhash"`
 		Username string `json:"username"`
@@ -162,33 +199,19 @@ func (thisRef client) LoginWithAuthHashIgnoreCache(username string, authHash str
 
 	if response.Status == apiContracts.API_ERROR_CODE_STATUS_FALSE {
 		// [0102] The username or password are invalid
-		if strings.Contains(response.Reason, apiContracts.API_ERROR_CODE_REASON_USER_OR_PASSWORD_INVALID) {
-			return apiContracts.Authentication{}, apiContracts.ErrAPI_Auth_AuthHashInvalid
-		}
-		if strings.Contains(response.Reason, apiContracts.API_ERROR_CODE_REASON_MISSING_USER) {
-			return apiContracts.Authentication{}, apiContracts.ErrAPI_Auth_NoSuchUser
-		}
-		if !(len(strings.TrimSpace(response.Reason)) <= 0) {
-			return apiContracts.Authentication{}, errorx.New(apiContracts.ErrAPI_Auth_Generic, response.Reason)
-		}
-		return apiContracts.Authentication{}, apiContracts.ErrAPI_Auth_Unknown
+		return apiContracts.Authentication{}, apiContracts.ParseAPIError(response.Reason).ToError(loginAuthHashAPIErrors, apiContracts.ErrAPI_Auth_Generic, apiContracts.ErrAPI_Auth_Unknown)
 	}
 
 	if response.Token == "" {
 		return apiContracts.Authentication{}, apiContracts.ErrAPI_Auth_NoToken
 	}
 
-	cachedAuthResponseMutex.Lock()
-	defer cachedAuthResponseMutex.Unlock()
-
-	cachedAuthResponse = apiContracts.Authentication{
+	return apiContracts.Authentication{
 		AuthHash: response.ServiceAuthHash,
 		User:     username,
 		UserID:   response.GUID,
 		Token:    response.Token,
-	}
-	cachedAuthResponseCreateTime = time.Now()
-	return cachedAuthResponse, nil
+	}, nil
 }
 
 func (thisRef client) ExpireAuthHash() {
@@ -203,19 +226,56 @@ func (thisRef client) Get(endpointURL string) ([]byte, errorx.Error) {
 	return thisRef.prepAndDoHTTPRequest("GET", endpointURL, nil)
 }
 
-func (thisRef client) prepAndDoHTTPRequest(method string, endpointURL string, payload []byte) ([]byte, errorx.Error) {
+// Download streams the reply into `writer`, the client timeout only applies while waiting for
+// the reply and between two reads of it so a large download doesn't have to fit in it.
+func (thisRef client) Download(endpointURL string, writer io.Writer) (int64, errorx.Error) {
+	headers, errx := thisRef.headers()
+	if errx != nil {
+		return 0, errx
+	}
+
+	_, written, err := doHTTPRequestToWriterWithSession(thisRef.context(), thisRef.tokens, thisRef.transport, "GET", headers, thisRef.apiURL+endpointURL, nil, thisRef.apiTimeout, writer)
+	if err != nil {
+		return written, apiContracts.ErrAPI_Client_Error
+	}
+
+	return written, nil
+}
+
+func (thisRef client) headers() (map[string]string, errorx.Error) {
 	headers := map[string]string{
 		"User-Agent": thisRef.userAgent,
 		"apikey":     thisRef.apiKey,
 	}
 
+	if thisRef.tokens != nil {
+		token, errx := thisRef.tokens.Token()
+		if errx != nil {
+			return nil, errx
+		}
+		if token != "" {
+			headers["token"] = token
+		}
+
+		return headers, nil
+	}
+
 	cachedAuthResponseMutex.Lock()
 	if cachedAuthResponse.Token != "" {
 		headers["token"] = cachedAuthResponse.Token
 	}
 	cachedAuthResponseMutex.Unlock()
 
-	_, data, err := doHTTPRequest(method, headers, thisRef.apiURL+endpointURL, payload, thisRef.apiTimeout)
+	return headers, nil
+}
+
+func (thisRef client) prepAndDoHTTPRequest(method string, endpointURL string, payload []byte) ([]byte, errorx.Error) {
+	headers, errx := thisRef.headers()
+	if errx != nil {
+		return nil, errx
+	}
+
+	_, data, err := doHTTPRequestWithSession(thisRef.context(), thisRef.tokens, thisRef.transport, method, headers, thisRef.apiURL+endpointURL, payload, thisRef.apiTimeout)
 	if err != nil {
 		return nil, apiContracts.ErrAPI_Client_Error
 	}
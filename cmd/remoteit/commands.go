@@ -0,0 +1,394 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+var certificateKeyTypes = map[string]apiContracts.CertificateKeyType{
+	"ecdsa-p256": apiContracts.CertificateKeyECDSAP256,
+	"ecdsa-p384": apiContracts.CertificateKeyECDSAP384,
+	"rsa-2048":   apiContracts.CertificateKeyRSA2048,
+	"rsa-4096":   apiContracts.CertificateKeyRSA4096,
+}
+
+func runLogin(args []string, stdout io.Writer) error {
+	flags, opts := newFlagSet("login")
+	if err := opts.parse(flags, args); err != nil {
+		return err
+	}
+
+	authentication, err := opts.login(opts.client())
+	if err != nil {
+		return err
+	}
+
+	return printResult(stdout, opts.output, result{
+		value:   authentication,
+		columns: []string{"USER", "USER ID", "AUTH HASH", "TOKEN"},
+		rows:    [][]string{{authentication.User, authentication.UserID, authentication.AuthHash, authentication.Token}},
+	})
+}
+
+func runDeviceList(args []string, stdout io.Writer) error {
+	flags, opts := newFlagSet("device list")
+	if err := opts.parse(flags, args); err != nil {
+		return err
+	}
+
+	client := opts.client()
+	if _, err := opts.login(client); err != nil {
+		return err
+	}
+
+	response, errx := api.NewDevice(client).ListAll()
+	if errx != nil {
+		return errx
+	}
+
+	res := result{
+		value:   response.Devices,
+		columns: []string{"UID", "NAME", "TYPE", "OWNER"},
+	}
+	for _, device := range response.Devices {
+		res.rows = append(res.rows, []string{device.DeviceAddress, device.DeviceAlias, device.DeviceType, device.OwnerUserName})
+	}
+
+	return printResult(stdout, opts.output, res)
+}
+
+func runDeviceTransfer(args []string, stdout io.Writer) error {
+	flags, opts := newFlagSet("device transfer")
+	uid := flags.String("uid", "", "UID of the device")
+	to := flags.String("to", "", "username of the destination account")
+	if err := opts.parse(flags, args); err != nil {
+		return err
+	}
+	if err := requireFlags("uid", *uid, "to", *to); err != nil {
+		return err
+	}
+
+	client := opts.client()
+	if _, err := opts.login(client); err != nil {
+		return err
+	}
+
+	if errx := api.NewDevice(client).Transfer(*uid, *to); errx != nil {
+		return errx
+	}
+
+	return printDone(stdout, opts.output, *uid, "transferred")
+}
+
+func runDeviceUnregister(args []string, stdout io.Writer) error {
+	flags, opts := newFlagSet("device unregister")
+	uid := flags.String("uid", "", "UID of the device")
+	if err := opts.parse(flags, args); err != nil {
+		return err
+	}
+	if err := requireFlag("uid", *uid); err != nil {
+		return err
+	}
+
+	client := opts.client()
+	if _, err := opts.login(client); err != nil {
+		return err
+	}
+
+	if errx := api.NewDevice(client).Unregister(*uid); errx != nil {
+		return errx
+	}
+
+	return printDone(stdout, opts.output, *uid, "unregistered")
+}
+
+func runServiceCreate(args []string, stdout io.Writer) error {
+	flags, opts := newFlagSet("service create")
+	uid := flags.String("uid", "", "UID of the service")
+	serviceType := flags.String("type", apiContracts.DefaultServiceType, "service type")
+	if err := opts.parse(flags, args); err != nil {
+		return err
+	}
+	if err := requireFlag("uid", *uid); err != nil {
+		return err
+	}
+
+	client := opts.client()
+	if _, err := opts.login(client); err != nil {
+		return err
+	}
+
+	if errx := api.NewService(client).Create(*uid, *serviceType); errx != nil {
+		return errx
+	}
+
+	return printDone(stdout, opts.output, *uid, "created")
+}
+
+func runServiceRemove(args []string, stdout io.Writer) error {
+	flags, opts := newFlagSet("service remove")
+	uid := flags.String("uid", "", "UID of the service")
+	if err := opts.parse(flags, args); err != nil {
+		return err
+	}
+	if err := requireFlag("uid", *uid); err != nil {
+		return err
+	}
+
+	client := opts.client()
+	if _, err := opts.login(client); err != nil {
+		return err
+	}
+
+	if errx := api.NewService(client).Remove(*uid); errx != nil {
+		return errx
+	}
+
+	return printDone(stdout, opts.output, *uid, "removed")
+}
+
+func runProxyConnect(args []string, stdout io.Writer) error {
+	flags, opts := newFlagSet("proxy connect")
+	uid := flags.String("uid", "", "UID of the service")
+	hostIP := flags.String("host-ip", apiContracts.DEFAULT_PROXY_CREATE_IP_LATCHING, "IP allowed to use the connection")
+	if err := opts.parse(flags, args); err != nil {
+		return err
+	}
+	if err := requireFlag("uid", *uid); err != nil {
+		return err
+	}
+
+	client := opts.client()
+	if _, err := opts.login(client); err != nil {
+		return err
+	}
+
+	response, errx := api.NewProxy(client).Create(apiContracts.CreateProxyRequest{
+		DeviceAddress: *uid,
+		HostIP:        *hostIP,
+		Wait:          apiContracts.DEFAULT_PROXY_CREATE_WAIT,
+		Isolate:       apiContracts.DEFAULT_PROXY_CREATE_ISOLATE,
+		Concurrent:    apiContracts.DEFAULT_PROXY_CREATE_CONCURRENT,
+	})
+	if errx != nil {
+		return errx
+	}
+
+	connection := response.Connection
+	return printResult(stdout, opts.output, result{
+		value:   connection,
+		columns: []string{"CONNECTION ID", "UID", "PROXY"},
+		rows:    [][]string{{connection.ConnectionID, connection.TargetUID, connection.ProxyServer + ":" + connection.ProxyPort}},
+	})
+}
+
+func runProxyDisconnect(args []string, stdout io.Writer) error {
+	flags, opts := newFlagSet("proxy disconnect")
+	uid := flags.String("uid", "", "UID of the service")
+	connectionID := flags.String("connection-id", "", "ID of the connection")
+	if err := opts.parse(flags, args); err != nil {
+		return err
+	}
+	if err := requireFlags("uid", *uid, "connection-id", *connectionID); err != nil {
+		return err
+	}
+
+	client := opts.client()
+	if _, err := opts.login(client); err != nil {
+		return err
+	}
+
+	if _, errx := api.NewProxy(client).Delete(apiContracts.DeleteProxyRequest{DeviceAddress: *uid, ConnectionID: *connectionID}); errx != nil {
+		return errx
+	}
+
+	return printDone(stdout, opts.output, *uid, "disconnected")
+}
+
+func runCertificateGenerate(args []string, stdout io.Writer) error {
+	flags, opts := newFlagSet("certificate generate")
+	request := apiContracts.CertificateRequest{}
+	flags.StringVar(&request.MachineID, "machine-id", "", "machine ID of the device")
+	flags.StringVar(&request.ServiceID, "service-id", "", "UID of the service")
+	flags.StringVar(&request.Name, "name", "", "certificate name")
+	flags.StringVar(&request.IP, "ip", "", "IP or host name the certificate is for")
+	localKey := flags.String("local-key", "", "generate the key locally: ecdsa-p256, ecdsa-p384, rsa-2048 or rsa-4096")
+	certificateOut := flags.String("certificate-out", "", "write the PEM certificate to this file")
+	keyOut := flags.String("key-out", "", "write the PEM key to this file")
+	if err := opts.parse(flags, args); err != nil {
+		return err
+	}
+	if err := requireFlags("machine-id", request.MachineID, "service-id", request.ServiceID); err != nil {
+		return err
+	}
+
+	client := opts.client()
+	authentication, err := opts.login(client)
+	if err != nil {
+		return err
+	}
+
+	certificateClient := api.NewCertificateClient(opts.certificateURL, authentication.Token, opts.timeout, opts.userAgent)
+
+	var response *apiContracts.CertificateResponse
+	var errx error
+	if *localKey != "" {
+		keyType, ok := certificateKeyTypes[*localKey]
+		if !ok {
+			return fmt.Errorf("unknown key type %q", *localKey)
+		}
+		response, errx = generateWithLocalKey(certificateClient, request, keyType)
+	} else {
+		response, errx = generate(certificateClient, request)
+	}
+	if errx != nil {
+		return errx
+	}
+
+	parsed, perrx := response.Parse()
+	if perrx != nil {
+		return perrx
+	}
+
+	if *certificateOut != "" {
+		if err := ioutil.WriteFile(*certificateOut, []byte(response.Certificate), 0644); err != nil {
+			return err
+		}
+	}
+	if *keyOut != "" {
+		if err := ioutil.WriteFile(*keyOut, []byte(response.Key), 0600); err != nil {
+			return err
+		}
+	}
+
+	return printResult(stdout, opts.output, result{
+		value:   response,
+		columns: []string{"CN", "NOT BEFORE", "NOT AFTER"},
+		rows:    [][]string{{parsed.CommonName, parsed.NotBefore.String(), parsed.NotAfter.String()}},
+	})
+}
+
+func runRestore(args []string, stdout io.Writer) error {
+	flags, opts := newFlagSet("restore")
+	deviceID := flags.String("device-id", "", "UID of the device")
+	machineID := flags.String("machine-id", "", "machine ID of the device")
+	if err := opts.parse(flags, args); err != nil {
+		return err
+	}
+	if err := requireFlags("device-id", *deviceID, "machine-id", *machineID); err != nil {
+		return err
+	}
+
+	client := opts.client()
+	authentication, err := opts.login(client)
+	if err != nil {
+		return err
+	}
+
+	config, errx := api.NewRestoreClient(opts.restoreURL, authentication.Token, opts.timeout, opts.userAgent).Restore(*deviceID, *machineID)
+	if errx != nil {
+		return errx
+	}
+
+	res := result{
+		value:   json.RawMessage(config.Raw),
+		columns: []string{"UID", "NAME", "TYPE", "HOSTNAME", "PORT", "DISABLED"},
+		rows:    [][]string{{config.DeviceUID, config.DeviceName, "", "", "", ""}},
+	}
+	for _, service := range config.Services {
+		res.rows = append(res.rows, []string{service.UID, config.Names[service.UID], strconv.Itoa(service.Type), service.Hostname, strconv.Itoa(service.Port), strconv.FormatBool(service.Disabled)})
+	}
+
+	return printResult(stdout, opts.output, res)
+}
+
+func runAutoRegister(args []string, stdout io.Writer) error {
+	flags, opts := newFlagSet("autoregister")
+	registrationKey := flags.String("registration-key", "", "bulk registration key")
+	hardwareID := flags.String("hardware-id", "", "hardware ID, collected from this machine when empty")
+	version := flags.String("version", "", "agent version to report")
+	statePath := flags.String("state", "", "file keeping the registration state between runs")
+	if err := opts.parse(flags, args); err != nil {
+		return err
+	}
+	if err := requireFlag("registration-key", *registrationKey); err != nil {
+		return err
+	}
+
+	request := apiContracts.AutoRegistrationRequest{
+		RegistrationKey: *registrationKey,
+		HardwareID:      *hardwareID,
+		Version:         *version,
+	}
+	if request.HardwareID == "" {
+		deviceInfo, errx := api.NewLinuxHardwareIdentityCollector("").Collect(*version)
+		if errx != nil {
+			return errx
+		}
+		request = deviceInfo.AutoRegistrationRequest(*registrationKey)
+	}
+
+	autoRegistration := api.NewAutoRegistration(opts.client())
+	if *statePath != "" {
+		autoRegistration = api.NewAutoRegistrationWithStateStore(opts.client(), api.NewFileAutoRegistrationStateStore(*statePath))
+	}
+
+	services, errx := autoRegistration.AutoRegisterIfNeeded(request)
+	if errx != nil {
+		return errx
+	}
+
+	res := result{
+		value:   services,
+		columns: []string{"TEMPLATE", "UID", "TYPE", "HOSTNAME", "PORT"},
+	}
+	for _, service := range services {
+		res.rows = append(res.rows, []string{service.TemplateID, service.UID, strconv.Itoa(service.Type), service.Hostname, strconv.Itoa(service.Port)})
+	}
+
+	return printResult(stdout, opts.output, res)
+}
+
+// generate and generateWithLocalKey keep a nil errorx.Error from becoming a non nil error.
+func generate(client api.CertificateClient, request apiContracts.CertificateRequest) (*apiContracts.CertificateResponse, error) {
+	response, errx := client.Generate(request)
+	if errx != nil {
+		return nil, errx
+	}
+
+	return response, nil
+}
+
+func generateWithLocalKey(client api.CertificateClient, request apiContracts.CertificateRequest, keyType apiContracts.CertificateKeyType) (*apiContracts.CertificateResponse, error) {
+	response, errx := client.GenerateWithLocalKey(request, keyType)
+	if errx != nil {
+		return nil, errx
+	}
+
+	return response, nil
+}
+
+func printDone(stdout io.Writer, output string, uid string, status string) error {
+	return printResult(stdout, output, result{
+		value:   map[string]string{"uid": uid, "status": status},
+		columns: []string{"UID", "STATUS"},
+		rows:    [][]string{{uid, status}},
+	})
+}
+
+// requireFlags takes name, value pairs.
+func requireFlags(namesAndValues ...string) error {
+	for i := 0; i+1 < len(namesAndValues); i += 2 {
+		if err := requireFlag(namesAndValues[i], namesAndValues[i+1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
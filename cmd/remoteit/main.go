@@ -0,0 +1,96 @@
+// Command remoteit drives the remote.it API through the SDK.
+//
+//	remoteit <command> [subcommand] [flags]
+//
+// Every command takes the credentials as flags, from a --config profile or from the
+// REMOTEIT_* environment variables and prints its result as --output json, table or yaml.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+type command struct {
+	usage string
+	run   func(args []string, stdout io.Writer) error
+}
+
+var commands = map[string]command{
+	"login":                {"log in and print the token", runLogin},
+	"device list":          {"list the devices of the account", runDeviceList},
+	"device transfer":      {"transfer a device to another account", runDeviceTransfer},
+	"device unregister":    {"unregister a device", runDeviceUnregister},
+	"service create":       {"create a service", runServiceCreate},
+	"service remove":       {"remove a service", runServiceRemove},
+	"proxy connect":        {"open a proxy connection to a service", runProxyConnect},
+	"proxy disconnect":     {"close a proxy connection", runProxyDisconnect},
+	"certificate generate": {"generate a certificate for a service", runCertificateGenerate},
+	"restore":              {"fetch the configuration of a device to restore", runRestore},
+	"autoregister":         {"run the bulk auto registration of this device", runAutoRegister},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	name, cmd, rest, ok := findCommand(args)
+	if !ok {
+		printUsage(stderr)
+		return 2
+	}
+
+	err := cmd.run(rest, stdout)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "remoteit %s: %s\n", name, err)
+		return 1
+	}
+
+	return 0
+}
+
+// findCommand matches "group command" before a single word command.
+func findCommand(args []string) (string, command, []string, bool) {
+	if len(args) >= 2 {
+		name := args[0] + " " + args[1]
+		if cmd, ok := commands[name]; ok {
+			return name, cmd, args[2:], true
+		}
+	}
+
+	if len(args) >= 1 {
+		if cmd, ok := commands[args[0]]; ok {
+			return args[0], cmd, args[1:], true
+		}
+	}
+
+	return "", command{}, nil, false
+}
+
+func printUsage(w io.Writer) {
+	names := []string{}
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "usage: remoteit <command> [flags]")
+	fmt.Fprintln(w)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-22s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "run `remoteit <command> -h` for the flags of a command, the credentials fall back to")
+	fmt.Fprintln(w, "the --config profile and to "+strings.Join([]string{apiContracts.ENV_APIKEY, apiContracts.ENV_USERNAME, apiContracts.ENV_PASSWORD, apiContracts.ENV_AUTHHASH}, ", "))
+}
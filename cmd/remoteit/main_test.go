@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/remoteit/sdk-go/apitest"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+func Test_CLI(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+
+	server.AddAccount(apitest.Account{Username: "user", Password: "pass", APIKey: "apikey"})
+	server.AddAccount(apitest.Account{Username: "other", Password: "other"})
+	device := server.AddDevice(apitest.Device{Name: "raspberrypi", HardwareID: "machine", Owner: "user"})
+	service := server.AddDevice(apitest.Device{Name: "ssh", Type: apiContracts.GetServiceType(apiContracts.DefaultServiceType, 28, 0, 0), HardwareID: "machine", Owner: "user", Port: 22})
+	server.AddBulkProject(apitest.BulkProject{RegistrationKey: "REGKEY", Owner: "user", Templates: []apitest.BulkTemplate{{ID: "T1", Type: 28, Port: "22"}}})
+
+	common := []string{"--api-url", server.URL, "--restore-url", server.RestoreURL, "--certificate-url", server.CertificateURL, "--apikey", "apikey", "--username", "user", "--password", "pass"}
+	cli := func(args ...string) (string, int) {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+
+		code := run(append(args, common...), stdout, stderr)
+		if code != 0 {
+			return stderr.String(), code
+		}
+
+		return stdout.String(), code
+	}
+
+	// 1. json
+	out, code := cli("login", "--output", "json")
+	var authentication apiContracts.Authentication
+	if code != 0 || json.Unmarshal([]byte(out), &authentication) != nil || authentication.Token == "" {
+		t.Errorf("unexpected login output %d %s", code, out)
+	}
+
+	// 2. table and yaml
+	out, code = cli("device", "list")
+	if code != 0 || !strings.HasPrefix(out, "UID") || !strings.Contains(out, service.UID) || strings.Count(out, "\n") != 3 {
+		t.Errorf("unexpected table %d\n%s", code, out)
+	}
+
+	out, code = cli("restore", "--device-id", device.UID, "--machine-id", "machine", "--output", "yaml")
+	if code != 0 || !strings.Contains(out, `uid: "`+device.UID+`"`) || !strings.Contains(out, "  port: 22") {
+		t.Errorf("unexpected yaml %d\n%s", code, out)
+	}
+
+	// 3. connect, disconnect
+	out, code = cli("proxy", "connect", "--uid", service.UID, "--output", "json")
+	var connection apiContracts.ProxyConnectionInfo
+	if code != 0 || json.Unmarshal([]byte(out), &connection) != nil || len(server.Connections()) != 1 {
+		t.Errorf("unexpected connect output %d %s", code, out)
+	}
+	if _, code = cli("proxy", "disconnect", "--uid", service.UID, "--connection-id", connection.ConnectionID); code != 0 || len(server.Connections()) != 0 {
+		t.Errorf("expected the connection to be closed, exit code %d", code)
+	}
+
+	// 4. certificate files
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	if out, code = cli("certificate", "generate", "--machine-id", "machine", "--service-id", service.UID, "--name", "ssh", "--local-key", "ecdsa-p256", "--key-out", keyPath); code != 0 {
+		t.Errorf("unexpected certificate output %d %s", code, out)
+	}
+	if key, _ := ioutil.ReadFile(keyPath); !strings.Contains(string(key), "PRIVATE KEY") {
+		t.Errorf("expected the key to be written, got %s", key)
+	}
+
+	// 5. auto registration
+	if out, code = cli("autoregister", "--registration-key", "REGKEY", "--hardware-id", "hardware"); code != 0 || !strings.Contains(out, "T1") {
+		t.Errorf("unexpected autoregister output %d %s", code, out)
+	}
+
+	// 6. transfer, then unregister fails
+	if _, code = cli("device", "transfer", "--uid", device.UID, "--to", "other"); code != 0 || len(server.Devices("other")) != 1 {
+		t.Errorf("expected the device to be transferred, exit code %d", code)
+	}
+	if out, code = cli("device", "unregister", "--uid", device.UID); code != 1 || !strings.Contains(out, "remoteit device unregister:") {
+		t.Errorf("expected unregister to fail, got %d %s", code, out)
+	}
+
+	// 7. usage errors
+	if out, code = cli("device", "list", "--output", "xml"); code != 1 || !strings.Contains(out, "unknown output") {
+		t.Errorf("expected an unknown output error, got %d %s", code, out)
+	}
+	if out, code = cli("device", "transfer", "--uid", device.UID); code != 1 || !strings.Contains(out, "missing --to") {
+		t.Errorf("expected a missing flag error, got %d %s", code, out)
+	}
+	if code := run([]string{"nope"}, &bytes.Buffer{}, &bytes.Buffer{}); code != 2 {
+		t.Errorf("expected the usage, got exit code %d", code)
+	}
+}
+
+func Test_CLI_YAML(t *testing.T) {
+	lines := yamlLines(map[string]interface{}{
+		"name":  "ssh",
+		"port":  json.Number("22"),
+		"empty": "",
+		"flag":  "true",
+		"list":  []interface{}{map[string]interface{}{"a": "b", "c": "d"}, "x"},
+		"none":  []interface{}{},
+	})
+
+	expected := []string{
+		`empty: ""`,
+		`flag: "true"`,
+		`list:`,
+		`  - a: b`,
+		`    c: d`,
+		`  - x`,
+		`name: ssh`,
+		`none: []`,
+		`port: 22`,
+	}
+
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+	}
+}
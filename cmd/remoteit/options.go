@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+// options are the flags every command takes. The flags that are not set come from the
+// --config profile, see api.LoadProfile.
+type options struct {
+	config         string
+	profile        string
+	apiURL         string
+	graphQLURL     string
+	restoreURL     string
+	certificateURL string
+	apiKey         string
+	username       string
+	password       string
+	authHash       string
+	timeout        time.Duration
+	userAgent      string
+	output         string
+}
+
+func newFlagSet(name string) (*flag.FlagSet, *options) {
+	opts := &options{}
+
+	flags := flag.NewFlagSet("remoteit "+name, flag.ContinueOnError)
+	flags.StringVar(&opts.config, "config", "", "config file of named profiles, TOML or JSON (default $"+apiContracts.ENV_CONFIG+")")
+	flags.StringVar(&opts.profile, "profile", "", "profile of the config file (default $"+apiContracts.ENV_PROFILE+", then the default profile)")
+	flags.StringVar(&opts.apiURL, "api-url", "", "REST API URL")
+	flags.StringVar(&opts.graphQLURL, "graphql-url", "", "GraphQL API URL")
+	flags.StringVar(&opts.restoreURL, "restore-url", "", "restore API URL")
+	flags.StringVar(&opts.certificateURL, "certificate-url", "", "certificate API URL")
+	flags.StringVar(&opts.apiKey, "apikey", "", "developer API key")
+	flags.StringVar(&opts.username, "username", "", "account username")
+	flags.StringVar(&opts.password, "password", "", "account password")
+	flags.StringVar(&opts.authHash, "authhash", "", "account auth hash, used instead of the password")
+	flags.DurationVar(&opts.timeout, "timeout", 0, "timeout of every request")
+	flags.StringVar(&opts.output, "output", outputTable, "output format: json, table or yaml")
+
+	return flags, opts
+}
+
+func (thisRef *options) parse(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := validateOutput(thisRef.output); err != nil {
+		return err
+	}
+
+	profile, errx := api.LoadProfile(thisRef.config, thisRef.profile)
+	if errx != nil {
+		return errx
+	}
+
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	fromProfile := map[string][2]*string{
+		"api-url":         {&thisRef.apiURL, &profile.APIURL},
+		"graphql-url":     {&thisRef.graphQLURL, &profile.GraphQLURL},
+		"restore-url":     {&thisRef.restoreURL, &profile.RestoreURL},
+		"certificate-url": {&thisRef.certificateURL, &profile.CertificateURL},
+		"apikey":          {&thisRef.apiKey, &profile.APIKey},
+		"username":        {&thisRef.username, &profile.Username},
+		"password":        {&thisRef.password, &profile.Password},
+		"authhash":        {&thisRef.authHash, &profile.AuthHash},
+	}
+	for name, fields := range fromProfile {
+		if !set[name] {
+			*fields[0] = *fields[1]
+		}
+	}
+
+	if !set["timeout"] {
+		thisRef.timeout = profile.Timeout
+	}
+	thisRef.userAgent = profile.UserAgent
+
+	return nil
+}
+
+func (thisRef *options) client() api.Client {
+	return api.NewClient(thisRef.apiURL, thisRef.apiKey, thisRef.timeout, thisRef.userAgent)
+}
+
+// login always ends with an auth hash login, it is the one that hands the token to every
+// REST call of the SDK.
+func (thisRef *options) login(client api.Client) (apiContracts.Authentication, error) {
+	if thisRef.username == "" {
+		return apiContracts.Authentication{}, fmt.Errorf("missing --username")
+	}
+
+	authHash := thisRef.authHash
+	if authHash == "" {
+		if thisRef.password == "" {
+			return apiContracts.Authentication{}, fmt.Errorf("missing --password or --authhash")
+		}
+
+		authentication, errx := client.LoginWithPassword(thisRef.username, thisRef.password)
+		if errx != nil {
+			return apiContracts.Authentication{}, errx
+		}
+
+		authHash = authentication.AuthHash
+	}
+
+	authentication, errx := client.LoginWithAuthHashIgnoreCache(thisRef.username, authHash)
+	if errx != nil {
+		return apiContracts.Authentication{}, errx
+	}
+
+	return authentication, nil
+}
+
+func requireFlag(name string, value string) error {
+	if value == "" {
+		return fmt.Errorf("missing --%s", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+	outputYAML  = "yaml"
+)
+
+// result is what a command prints, `value` as JSON or YAML and `columns` + `rows` as a table.
+type result struct {
+	value   interface{}
+	columns []string
+	rows    [][]string
+}
+
+func validateOutput(output string) error {
+	switch output {
+	case outputJSON, outputTable, outputYAML:
+		return nil
+	}
+
+	return fmt.Errorf("unknown output %q, use json, table or yaml", output)
+}
+
+func printResult(w io.Writer, output string, res result) error {
+	switch output {
+	case outputJSON:
+		data, err := json.MarshalIndent(res.value, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+
+	case outputYAML:
+		data, err := json.Marshal(res.value)
+		if err != nil {
+			return err
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(w, strings.Join(yamlLines(value), "\n"))
+		return err
+
+	default:
+		table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, strings.Join(res.columns, "\t"))
+		for _, row := range res.rows {
+			fmt.Fprintln(table, strings.Join(row, "\t"))
+		}
+		return table.Flush()
+	}
+}
+
+// yamlLines renders a decoded JSON value as block style YAML, keys are sorted.
+func yamlLines(value interface{}) []string {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if len(value) == 0 {
+			return []string{"{}"}
+		}
+
+		keys := []string{}
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		lines := []string{}
+		for _, key := range keys {
+			child := yamlLines(value[key])
+			if isYAMLInline(value[key]) {
+				lines = append(lines, yamlString(key)+": "+child[0])
+				continue
+			}
+
+			lines = append(lines, yamlString(key)+":")
+			for _, line := range child {
+				lines = append(lines, "  "+line)
+			}
+		}
+		return lines
+
+	case []interface{}:
+		if len(value) == 0 {
+			return []string{"[]"}
+		}
+
+		lines := []string{}
+		for _, item := range value {
+			for i, line := range yamlLines(item) {
+				if i == 0 {
+					lines = append(lines, "- "+line)
+				} else {
+					lines = append(lines, "  "+line)
+				}
+			}
+		}
+		return lines
+
+	case string:
+		return []string{yamlString(value)}
+	case json.Number:
+		return []string{value.String()}
+	case bool:
+		return []string{strconv.FormatBool(value)}
+	case nil:
+		return []string{"null"}
+	}
+
+	return []string{yamlString(fmt.Sprint(value))}
+}
+
+func isYAMLInline(value interface{}) bool {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return len(value) == 0
+	case []interface{}:
+		return len(value) == 0
+	}
+
+	return true
+}
+
+// yamlString quotes anything a YAML parser would not read back as the same plain string.
+func yamlString(value string) string {
+	if value == "" || strings.TrimSpace(value) != value || strings.ContainsAny(value, ":#\n\"'{}[],&*!|>%@`\\") {
+		return strconv.Quote(value)
+	}
+
+	switch strings.ToLower(value) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return strconv.Quote(value)
+	}
+
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return strconv.Quote(value)
+	}
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "?") {
+		return strconv.Quote(value)
+	}
+
+	return value
+}
@@ -0,0 +1,303 @@
+package collector
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+// Collector exports the devices, the proxy connections and the SDK metrics in the Prometheus
+// text format, mount it on `/metrics`. Pass it as `api.Instrumentation.Metrics` for the
+// METRIC_API_* series and wrap the proxies with `TrackProxy` for the connections:
+//
+//	metrics := collector.New(apiContracts.CollectorOptions{})
+//	sdk, errx := api.NewSDKWithInstrumentation(profile, nil, api.Instrumentation{Metrics: metrics})
+//	metrics.Watch(sdk.Devices(), sdk.GraphQL())
+//	metrics.Start()
+//	http.Handle("/metrics", metrics)
+//
+// The devices come from the REST device list, GraphQL names their application types and its
+// device listing reports the open connections. The connections opened through a TrackProxy
+// proxy show up before the next collect and also get their life left.
+type Collector interface {
+	http.Handler
+	api.Metrics
+
+	// Watch sets what `Collect` lists. Without `graphQL` the application type IDs are exported
+	// and only the tracked connections are counted.
+	Watch(devices api.Device, graphQL api.GraphQLClient)
+	TrackProxy(proxy api.Proxy) api.Proxy
+
+	Start()
+	Stop()
+	Collect() errorx.Error
+}
+
+func New(options apiContracts.CollectorOptions) Collector {
+	if options.Interval <= 0 {
+		options.Interval = apiContracts.DEFAULT_COLLECTOR_INTERVAL
+	}
+	if len(options.HistogramBuckets) == 0 {
+		options.HistogramBuckets = apiContracts.DefaultHistogramBuckets
+	}
+
+	return &collector{
+		options:     options,
+		counters:    map[string]map[string]*series{},
+		histograms:  map[string]map[string]*series{},
+		devices:     map[deviceKey]int{},
+		connections: map[string]trackedConnection{},
+		listed:      map[string]string{},
+	}
+}
+
+type deviceKey struct {
+	state string
+	kind  string
+	owner string
+}
+
+type collector struct {
+	options apiContracts.CollectorOptions
+
+	mutex         sync.Mutex
+	deviceClient  api.Device
+	graphQLClient api.GraphQLClient
+	counters      map[string]map[string]*series // by name, then by labels
+	histograms    map[string]map[string]*series
+	devices       map[deviceKey]int
+	connections   map[string]trackedConnection // by connection ID
+	listed        map[string]string            // connection ID -> service ID, from GraphQL
+	collected     bool
+	lastSuccess   bool
+	lastCollect   time.Time
+
+	stopMutex sync.Mutex
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func (thisRef *collector) Watch(devices api.Device, graphQL api.GraphQLClient) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	thisRef.deviceClient = devices
+	thisRef.graphQLClient = graphQL
+}
+
+// Start collects right away and then every `Interval` in the background until `Stop` is
+// called, the errors go to `OnError` and to METRIC_COLLECT_SUCCESS.
+func (thisRef *collector) Start() {
+	thisRef.stopMutex.Lock()
+	defer thisRef.stopMutex.Unlock()
+
+	if thisRef.stop != nil {
+		return
+	}
+
+	thisRef.stop = make(chan struct{})
+	thisRef.done = make(chan struct{})
+	go thisRef.run(thisRef.stop, thisRef.done)
+}
+
+func (thisRef *collector) Stop() {
+	thisRef.stopMutex.Lock()
+	defer thisRef.stopMutex.Unlock()
+
+	if thisRef.stop == nil {
+		return
+	}
+
+	close(thisRef.stop)
+	<-thisRef.done
+
+	thisRef.stop = nil
+	thisRef.done = nil
+}
+
+func (thisRef *collector) run(stop chan struct{}, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(thisRef.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		if errx := thisRef.Collect(); errx != nil && thisRef.options.OnError != nil {
+			thisRef.options.OnError(errx)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Collect lists the devices and the open connections once. The counts of the last successful
+// listing keep being exported when one fails.
+func (thisRef *collector) Collect() errorx.Error {
+	thisRef.mutex.Lock()
+	deviceClient, graphQLClient := thisRef.deviceClient, thisRef.graphQLClient
+	thisRef.mutex.Unlock()
+
+	if deviceClient == nil {
+		return nil
+	}
+
+	response, errx := deviceClient.ListAll()
+	if errx != nil {
+		thisRef.collectDone(nil, nil, errx)
+		return errx
+	}
+
+	// without the application type names the IDs are exported
+	names := map[int]string{}
+	var listed map[string]string
+	if graphQLClient != nil {
+		applicationTypes, graphQLErrx := graphQLClient.GetApplicationTypes()
+		for _, applicationType := range applicationTypes {
+			names[applicationType.ID] = applicationType.Name
+		}
+		errx = graphQLErrx
+
+		listedDevices, graphQLErrx := graphQLClient.GetDevices()
+		if graphQLErrx == nil {
+			listed = listedConnections(listedDevices)
+		} else if errx == nil {
+			errx = graphQLErrx
+		}
+	}
+
+	devices := map[deviceKey]int{}
+	for _, device := range response.Devices {
+		devices[deviceKey{
+			state: orUnknown(device.DeviceState),
+			kind:  applicationTypeLabel(device.DeviceType, names),
+			owner: orUnknown(device.OwnerUserName),
+		}]++
+	}
+
+	thisRef.collectDone(devices, listed, errx)
+
+	return errx
+}
+
+// listedConnections maps the open connections of `devices` to their service.
+func listedConnections(devices []apiContracts.ListedDevice) map[string]string {
+	connections := map[string]string{}
+	for _, device := range devices {
+		for _, service := range device.Services {
+			for _, connectionID := range service.Connections {
+				connections[connectionID] = service.ID
+			}
+		}
+	}
+
+	return connections
+}
+
+func (thisRef *collector) collectDone(devices map[deviceKey]int, listed map[string]string, errx errorx.Error) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	if devices != nil {
+		thisRef.devices = devices
+	}
+	if listed != nil {
+		thisRef.listed = listed
+	}
+
+	thisRef.collected = true
+	thisRef.lastSuccess = errx == nil
+	thisRef.lastCollect = time.Now()
+}
+
+func (thisRef *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var buffer bytes.Buffer
+	for _, family := range thisRef.families() {
+		family.write(&buffer)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(buffer.Bytes())
+}
+
+func (thisRef *collector) families() []family {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	now := time.Now()
+	families := []family{}
+
+	devices := family{name: apiContracts.METRIC_DEVICES, help: "Devices by state, application type and owner.", kind: "gauge"}
+	for key, count := range thisRef.devices {
+		devices.add("", map[string]string{"state": key.state, "type": key.kind, "owner": key.owner}, float64(count))
+	}
+	families = append(families, devices)
+
+	connections := family{name: apiContracts.METRIC_PROXY_CONNECTIONS, help: "Open proxy connections by device.", kind: "gauge"}
+	lifeLeft := family{name: apiContracts.METRIC_PROXY_CONNECTION_LIFE_LEFT, help: "Seconds until a proxy connection opened by this process closes.", kind: "gauge"}
+	connectionsByDevice := map[string]int{}
+	for id, connection := range thisRef.connections {
+		if connection.expired(now) {
+			delete(thisRef.connections, id)
+			continue
+		}
+
+		connectionsByDevice[connection.device]++
+		if !connection.expiresAt.IsZero() {
+			lifeLeft.add("", map[string]string{"connection": id, "device": connection.device}, connection.expiresAt.Sub(now).Seconds())
+		}
+	}
+	for id, device := range thisRef.listed {
+		if _, tracked := thisRef.connections[id]; !tracked {
+			connectionsByDevice[device]++
+		}
+	}
+	for device, count := range connectionsByDevice {
+		connections.add("", map[string]string{"device": device}, float64(count))
+	}
+	families = append(families, connections, lifeLeft)
+
+	if thisRef.collected {
+		success := 0.0
+		if thisRef.lastSuccess {
+			success = 1
+		}
+
+		families = append(families,
+			family{name: apiContracts.METRIC_COLLECT_SUCCESS, help: "Whether the last device listing succeeded.", kind: "gauge", samples: []sample{{value: success}}},
+			family{name: apiContracts.METRIC_COLLECT_TIMESTAMP, help: "Unix time of the last device listing.", kind: "gauge", samples: []sample{{value: float64(thisRef.lastCollect.UnixNano()) / 1e9}}},
+		)
+	}
+
+	return append(families, thisRef.metricFamilies()...)
+}
+
+func applicationTypeLabel(deviceType string, names map[int]string) string {
+	id := apiContracts.GetApplicationTypeID(deviceType)
+	if id == apiContracts.InvalidApplicationType {
+		return "unknown"
+	}
+
+	if name, ok := names[id]; ok && name != "" {
+		return name
+	}
+
+	return strconv.Itoa(id)
+}
+
+func orUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+
+	return value
+}
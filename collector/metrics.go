@@ -0,0 +1,220 @@
+package collector
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+var metricHelp = map[string]string{
+	apiContracts.METRIC_API_REQUEST_DURATION:   "Duration of the SDK HTTP requests.",
+	apiContracts.METRIC_API_OPERATION_DURATION: "Duration of the SDK operations.",
+	apiContracts.METRIC_API_ERRORS:             "SDK operations that failed, by error code.",
+	apiContracts.METRIC_API_RETRIES:            "Retried attempts of the SDK operations.",
+}
+
+// series is one label set of a counter, or of a histogram with its cumulative bucket counts.
+type series struct {
+	labels  map[string]string
+	value   float64 // the histogram sum
+	count   uint64
+	buckets []uint64
+}
+
+func (thisRef *collector) AddCounter(name string, value float64, labels map[string]string) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	thisRef.series(thisRef.counters, name, labels).value += value
+}
+
+func (thisRef *collector) ObserveHistogram(name string, value float64, labels map[string]string) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	observed := thisRef.series(thisRef.histograms, name, labels)
+	if observed.buckets == nil {
+		observed.buckets = make([]uint64, len(thisRef.options.HistogramBuckets))
+	}
+
+	observed.value += value
+	observed.count++
+	for i, upperBound := range thisRef.options.HistogramBuckets {
+		if value <= upperBound {
+			observed.buckets[i]++
+		}
+	}
+}
+
+func (thisRef *collector) series(metrics map[string]map[string]*series, name string, labels map[string]string) *series {
+	byLabels, ok := metrics[name]
+	if !ok {
+		byLabels = map[string]*series{}
+		metrics[name] = byLabels
+	}
+
+	key := labelsKey(labels)
+	found, ok := byLabels[key]
+	if !ok {
+		copied := map[string]string{}
+		for label, value := range labels {
+			copied[label] = value
+		}
+
+		found = &series{labels: copied}
+		byLabels[key] = found
+	}
+
+	return found
+}
+
+// metricFamilies are the counters and histograms reported through `api.Metrics`.
+func (thisRef *collector) metricFamilies() []family {
+	families := []family{}
+
+	for name, byLabels := range thisRef.counters {
+		counter := family{name: name, help: metricHelp[name], kind: "counter"}
+		for _, counted := range byLabels {
+			counter.add("", counted.labels, counted.value)
+		}
+		families = append(families, counter)
+	}
+
+	for name, byLabels := range thisRef.histograms {
+		histogram := family{name: name, help: metricHelp[name], kind: "histogram"}
+		for _, observed := range byLabels {
+			for i, upperBound := range thisRef.options.HistogramBuckets {
+				histogram.add("_bucket", withLabel(observed.labels, "le", formatValue(upperBound)), float64(observed.buckets[i]))
+			}
+			histogram.add("_bucket", withLabel(observed.labels, "le", "+Inf"), float64(observed.count))
+			histogram.add("_sum", observed.labels, observed.value)
+			histogram.add("_count", observed.labels, float64(observed.count))
+		}
+		families = append(families, histogram)
+	}
+
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].name < families[j].name
+	})
+
+	return families
+}
+
+type family struct {
+	name    string
+	help    string
+	kind    string // counter, gauge or histogram
+	samples []sample
+}
+
+type sample struct {
+	suffix string // _bucket, _sum or _count of a histogram
+	labels map[string]string
+	value  float64
+}
+
+func (thisRef *family) add(suffix string, labels map[string]string, value float64) {
+	thisRef.samples = append(thisRef.samples, sample{suffix: suffix, labels: labels, value: value})
+}
+
+// write writes the family in the Prometheus text format 0.0.4, the samples of a histogram
+// stay grouped by label set.
+func (thisRef family) write(w io.Writer) {
+	if thisRef.help != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", thisRef.name, thisRef.help)
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", thisRef.name, thisRef.kind)
+
+	sort.SliceStable(thisRef.samples, func(i, j int) bool {
+		return labelsKey(withoutLabel(thisRef.samples[i].labels, "le")) < labelsKey(withoutLabel(thisRef.samples[j].labels, "le"))
+	})
+
+	for _, sample := range thisRef.samples {
+		fmt.Fprintf(w, "%s%s%s %s\n", thisRef.name, sample.suffix, formatLabels(sample.labels), formatValue(sample.value))
+	}
+}
+
+func labelNames(labels map[string]string) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		if name != "le" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	// `le` goes last, like the Prometheus clients write it
+	if _, ok := labels["le"]; ok {
+		names = append(names, "le")
+	}
+
+	return names
+}
+
+func labelsKey(labels map[string]string) string {
+	parts := []string{}
+	for _, name := range labelNames(labels) {
+		parts = append(parts, name+"="+labels[name])
+	}
+
+	return strings.Join(parts, "\xff")
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	parts := []string{}
+	for _, name := range labelNames(labels) {
+		parts = append(parts, name+`="`+labelValueEscaper.Replace(labels[name])+`"`)
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func withLabel(labels map[string]string, name string, value string) map[string]string {
+	copied := map[string]string{name: value}
+	for label, labelValue := range labels {
+		if label != name {
+			copied[label] = labelValue
+		}
+	}
+
+	return copied
+}
+
+func withoutLabel(labels map[string]string, name string) map[string]string {
+	if _, ok := labels[name]; !ok {
+		return labels
+	}
+
+	copied := map[string]string{}
+	for label, value := range labels {
+		if label != name {
+			copied[label] = value
+		}
+	}
+
+	return copied
+}
@@ -0,0 +1,90 @@
+package collector
+
+import (
+	"time"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+type trackedConnection struct {
+	device    string
+	expiresAt time.Time // zero when the API did not send a `lifeLeft`
+}
+
+func (thisRef trackedConnection) expired(now time.Time) bool {
+	return !thisRef.expiresAt.IsZero() && !now.Before(thisRef.expiresAt)
+}
+
+// TrackProxy returns `proxy` exporting the connections it opens until they are deleted or
+// their `lifeLeft` runs out. The GraphQL device listing doesn't tell the life left, so only
+// these get METRIC_PROXY_CONNECTION_LIFE_LEFT.
+func (thisRef *collector) TrackProxy(proxy api.Proxy) api.Proxy {
+	return &trackingProxy{
+		Proxy:     proxy,
+		collector: thisRef,
+	}
+}
+
+type trackingProxy struct {
+	api.Proxy
+	collector *collector
+}
+
+func (thisRef *trackingProxy) Create(request apiContracts.CreateProxyRequest) (apiContracts.CreateProxyResponse, errorx.Error) {
+	response, errx := thisRef.Proxy.Create(request)
+	if errx == nil {
+		thisRef.collector.track(request.DeviceAddress, response.Connection)
+	}
+
+	return response, errx
+}
+
+func (thisRef *trackingProxy) CreateAndCheckHealth(request apiContracts.CreateProxyRequest, healthRequest apiContracts.ProxyHealthCheckRequest) (apiContracts.CreateProxyResponse, apiContracts.ProxyHealthCheckResponse, errorx.Error) {
+	response, health, errx := thisRef.Proxy.CreateAndCheckHealth(request, healthRequest)
+
+	// a connection failing its health check is still open
+	if response.Connection.ConnectionID != "" {
+		thisRef.collector.track(request.DeviceAddress, response.Connection)
+	}
+
+	return response, health, errx
+}
+
+func (thisRef *trackingProxy) Delete(request apiContracts.DeleteProxyRequest) (apiContracts.DeleteProxyResponse, errorx.Error) {
+	response, errx := thisRef.Proxy.Delete(request)
+	if errx == nil {
+		thisRef.collector.untrack(request.ConnectionID)
+	}
+
+	return response, errx
+}
+
+func (thisRef *collector) track(device string, connection apiContracts.ProxyConnectionInfo) {
+	if connection.ConnectionID == "" {
+		return
+	}
+
+	if device == "" {
+		device = connection.TargetUID
+	}
+
+	tracked := trackedConnection{device: device}
+	if connection.LifeLeft > 0 {
+		tracked.expiresAt = time.Now().Add(time.Duration(connection.LifeLeft) * time.Second)
+	}
+
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	thisRef.connections[connection.ConnectionID] = tracked
+}
+
+func (thisRef *collector) untrack(connectionID string) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	delete(thisRef.connections, connectionID)
+	delete(thisRef.listed, connectionID)
+}
@@ -0,0 +1,83 @@
+package contracts
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+// APIError is the `reason` of a failed REST response split into the bracketed API code
+// and the human readable part, "[0806] device not found" gives `{"0806", "device not found"}`.
+type APIError struct {
+	Code   string // empty when the reason carries no code
+	Reason string
+	Raw    string // the reason as sent by the API
+}
+
+// APIErrorMapping turns an APIError into an SDK error, by code or, for the reasons that
+// come without one, by a case insensitive substring of the reason.
+type APIErrorMapping struct {
+	Code     string
+	Contains string
+	Error    errorx.Error
+}
+
+var apiErrorCodePattern = regexp.MustCompile(`\[(\w+)\]`)
+
+// ParseAPIError extracts the first bracketed code found anywhere in `reason`.
+func ParseAPIError(reason string) APIError {
+	apiError := APIError{
+		Reason: strings.TrimSpace(reason),
+		Raw:    reason,
+	}
+
+	if location := apiErrorCodePattern.FindStringSubmatchIndex(reason); location != nil {
+		apiError.Code = reason[location[2]:location[3]]
+		apiError.Reason = strings.Join(strings.Fields(reason[:location[0]]+" "+reason[location[1]:]), " ")
+	}
+
+	return apiError
+}
+
+// ParseAPIErrorFromResponse reads the `status` and `reason` of any REST response, the
+// second return value is false when the status reports success or can't be read.
+func ParseAPIErrorFromResponse(raw []byte) (APIError, bool) {
+	var response struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	}
+
+	if err := json.Unmarshal(raw, &response); err != nil || response.Status == API_ERROR_CODE_STATUS_TRUE {
+		return APIError{}, false
+	}
+
+	return ParseAPIError(response.Reason), true
+}
+
+func (e APIError) IsEmpty() bool {
+	return e.Code == "" && e.Reason == ""
+}
+
+// ToError returns the first matching SDK error from `mappings`. Anything else becomes a
+// `genericCode` error carrying the reason, or `unknown` when there is no reason at all.
+func (e APIError) ToError(mappings []APIErrorMapping, genericCode int, unknown errorx.Error) errorx.Error {
+	for _, mapping := range mappings {
+		if mapping.Code != "" && mapping.Code == e.Code {
+			return mapping.Error
+		}
+		if mapping.Contains != "" && strings.Contains(strings.ToLower(e.Raw), strings.ToLower(mapping.Contains)) {
+			return mapping.Error
+		}
+	}
+
+	if e.IsEmpty() && unknown != nil {
+		return unknown
+	}
+
+	errx := NewReasonError(genericCode, strings.TrimSpace(e.Raw))
+	errx.APICode = e.Code
+
+	return errx
+}
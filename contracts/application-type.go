@@ -61,6 +61,17 @@ func GetServiceType(deviceType string, applicationType int, manufacturer int, pl
 	return formatHex(deviceTypeAsBytes, 2)
 }
 
+// GetApplicationTypeID reads the application type back from the first two bytes of a
+// service type, InvalidApplicationType when it has none.
+func GetApplicationTypeID(serviceType string) int {
+	serviceTypeAsBytes := parseHex(serviceType)
+	if len(serviceTypeAsBytes) < 2 {
+		return InvalidApplicationType
+	}
+
+	return int(serviceTypeAsBytes[0])<<8 | int(serviceTypeAsBytes[1])
+}
+
 func intToHex(n int) []byte {
 	return parseHex(fmt.Sprintf("%04x", n))
 }
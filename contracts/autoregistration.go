@@ -1,13 +1,91 @@
 package contracts
 
+import "time"
+
 type ServiceCredentials struct {
 	UID    string `json:"uid"`
 	Secret string `json:"secret"`
 }
 
 type ServiceConfigResponse struct {
-	Hostname string
-	Port     int
-	Type     int
-	Disabled bool
+	Hostname  string
+	Port      int   // the first entry of Ports, 0 for a multi-port service without ports
+	Ports     []int // more than one entry only for multi-port services
+	Type      int
+	Protocol  Protocol
+	Disabled  bool
+	ProjectID string
+	Timestamp string
+}
+
+func (s ServiceConfigResponse) IsMultiPort() bool {
+	return s.Type == BulkServiceID || s.Type == MultiPortServiceID
+}
+
+type AutoRegistrationRequest struct {
+	RegistrationKey string
+	HardwareID      string
+	CPUID           string
+	MACAddress      string
+	Version         string
+	PlatformOSName  string
+
+	MaxAttempts    int           // DEFAULT_AUTOREG_MAX_ATTEMPTS when zero
+	InitialBackoff time.Duration // DEFAULT_AUTOREG_INITIAL_BACKOFF when zero
+	MaxBackoff     time.Duration // DEFAULT_AUTOREG_MAX_BACKOFF when zero
+}
+
+type AutoRegistrationState struct {
+	RegistrationKey    string                        `json:"registrationKey"`
+	HardwareID         string                        `json:"hardwareID"`         // as passed in the AutoRegistrationRequest
+	ResolvedHardwareID string                        `json:"resolvedHardwareID"` // as handed out by a "reset" status, if any
+	Registered         map[string]ServiceCredentials `json:"registered"`         // template ID -> credentials
+	Pending            []string                      `json:"pending"`            // template IDs not registered yet
+}
+
+func NewAutoRegistrationState(registrationKey string, hardwareID string) AutoRegistrationState {
+	return AutoRegistrationState{
+		RegistrationKey: registrationKey,
+		HardwareID:      hardwareID,
+		Registered:      map[string]ServiceCredentials{},
+		Pending:         []string{},
+	}
+}
+
+type DeviceInfo struct {
+	HardwareID     string `json:"hardwareID"`
+	MachineID      string `json:"machineID"`
+	CPUID          string `json:"cpuID"`
+	MACAddress     string `json:"macAddress"`
+	Version        string `json:"version"`
+	PlatformOSName string `json:"platformOSName"`
+}
+
+func (d DeviceInfo) AutoRegistrationRequest(registrationKey string) AutoRegistrationRequest {
+	return AutoRegistrationRequest{
+		RegistrationKey: registrationKey,
+		HardwareID:      d.HardwareID,
+		CPUID:           d.CPUID,
+		MACAddress:      d.MACAddress,
+		Version:         d.Version,
+		PlatformOSName:  d.PlatformOSName,
+	}
+}
+
+type ComponentVersion struct {
+	Name    string `json:"name"`    // "connectd"
+	Version string `json:"version"` // "4.9"
+}
+
+type ProjectEnablement struct {
+	ProjectID string
+	Enabled   bool
+}
+
+type ProvisioningInfo struct {
+	ProjectID string
+	Version   string
+	FileName  string
+	Size      int64
+	Checksum  string // hex encoded SHA-256 of the provisioning bundle
 }
@@ -0,0 +1,143 @@
+package contracts
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"time"
+
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+type ParsedCertificate struct {
+	Leaf           *x509.Certificate
+	Chain          []*x509.Certificate // the leaf first, then any intermediates sent along
+	TLSCertificate tls.Certificate
+
+	CommonName  string
+	NotBefore   time.Time
+	NotAfter    time.Time
+	DNSNames    []string
+	IPAddresses []net.IP
+}
+
+// Parse decodes the PEM certificate and key and makes sure they belong together.
+func (r CertificateResponse) Parse() (ParsedCertificate, errorx.Error) {
+	chain, derChain, err := parseCertificateChain(r.Certificate)
+	if err != nil {
+		return ParsedCertificate{}, NewErrorFromErr(ErrAPI_CertClient_CantParseCert.Code(), err)
+	}
+
+	key, err := parsePrivateKey(r.Key)
+	if err != nil {
+		return ParsedCertificate{}, NewErrorFromErr(ErrAPI_CertClient_CantParseKey.Code(), err)
+	}
+
+	leaf := chain[0]
+	if !publicKeyMatches(leaf.PublicKey, key.Public()) {
+		return ParsedCertificate{}, ErrAPI_CertClient_KeyMismatch
+	}
+
+	return ParsedCertificate{
+		Leaf:  leaf,
+		Chain: chain,
+		TLSCertificate: tls.Certificate{
+			Certificate: derChain,
+			PrivateKey:  key,
+			Leaf:        leaf,
+		},
+		CommonName:  leaf.Subject.CommonName,
+		NotBefore:   leaf.NotBefore,
+		NotAfter:    leaf.NotAfter,
+		DNSNames:    leaf.DNSNames,
+		IPAddresses: leaf.IPAddresses,
+	}, nil
+}
+
+func (p ParsedCertificate) IsValidAt(t time.Time) bool {
+	return !t.Before(p.NotBefore) && t.Before(p.NotAfter)
+}
+
+func (p ParsedCertificate) Lifetime() time.Duration {
+	return p.NotAfter.Sub(p.NotBefore)
+}
+
+// TLSConfig returns a server config serving this certificate, for services exposed through remote.it.
+func (p ParsedCertificate) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{p.TLSCertificate},
+	}
+}
+
+func parseCertificateChain(certificatePEM string) ([]*x509.Certificate, [][]byte, error) {
+	chain := []*x509.Certificate{}
+	derChain := [][]byte{}
+
+	rest := []byte(certificatePEM)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		chain = append(chain, certificate)
+		derChain = append(derChain, block.Bytes)
+	}
+
+	if len(chain) == 0 {
+		return nil, nil, fmt.Errorf("no certificate found in PEM data")
+	}
+
+	return chain, derChain, nil
+}
+
+func parsePrivateKey(keyPEM string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return nil, fmt.Errorf("no private key found in PEM data")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		}
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unsupported private key format %q", block.Type)
+}
+
+func publicKeyMatches(certificateKey crypto.PublicKey, privateKeyPublic crypto.PublicKey) bool {
+	switch key := certificateKey.(type) {
+	case *rsa.PublicKey:
+		return key.Equal(privateKeyPublic)
+	case *ecdsa.PublicKey:
+		return key.Equal(privateKeyPublic)
+	case ed25519.PublicKey:
+		return key.Equal(privateKeyPublic)
+	default:
+		return false
+	}
+}
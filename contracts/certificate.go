@@ -1,10 +1,17 @@
 package contracts
 
+import (
+	"time"
+
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
 type CertificateRequest struct {
 	MachineID string `json:"machineId"`
 	ServiceID string `json:"serviceId"`
 	Name      string `json:"name,omitempty"`
 	IP        string `json:"ip,omitempty"`
+	CSR       string `json:"csr,omitempty"` // PEM encoded, the server signs it and returns no key
 }
 
 type CertificateResponse struct {
@@ -12,3 +19,27 @@ type CertificateResponse struct {
 	Certificate string `json:"certificate"`
 	Key         string `json:"key"`
 }
+
+type CertificateKeyType int
+
+const (
+	CertificateKeyECDSAP256 CertificateKeyType = iota
+	CertificateKeyECDSAP384
+	CertificateKeyRSA2048
+	CertificateKeyRSA4096
+)
+
+type CertificateRenewerOptions struct {
+	Request         CertificateRequest
+	CertificatePath string
+	KeyPath         string
+
+	UseLocalKey bool               // uses GenerateWithLocalKey instead of Generate
+	KeyType     CertificateKeyType // only used with UseLocalKey
+
+	RenewAtFraction float64       // DEFAULT_CERTIFICATE_RENEW_AT_FRACTION of the lifetime when zero
+	RetryInterval   time.Duration // DEFAULT_CERTIFICATE_RENEW_RETRY_INTERVAL when zero
+
+	OnRenewed func(certificate ParsedCertificate)
+	OnError   func(err errorx.Error)
+}
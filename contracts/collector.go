@@ -0,0 +1,20 @@
+package contracts
+
+import (
+	"time"
+
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+type CollectorOptions struct {
+	Interval time.Duration // DEFAULT_COLLECTOR_INTERVAL when zero
+
+	// upper bounds of the histogram buckets of the METRIC_API_* durations, in seconds,
+	// DefaultHistogramBuckets when empty
+	HistogramBuckets []float64
+
+	OnError func(err errorx.Error)
+}
+
+// DefaultHistogramBuckets are the default Prometheus client buckets.
+var DefaultHistogramBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
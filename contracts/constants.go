@@ -15,13 +15,70 @@ const (
 	DEFAULT_PROXY_CREATE_ISOLATE     = "domain=app.remote.it"
 	DEFAULT_PROXY_CREATE_CONCURRENT  = true
 
+	DEFAULT_PROXY_HEALTH_CHECK_TIMEOUT   = 10 * time.Second
+	DEFAULT_PROXY_HEALTH_CHECK_HTTP_PATH = "/"
+
+	DEFAULT_AUTOREG_MAX_ATTEMPTS    = 10
+	DEFAULT_AUTOREG_INITIAL_BACKOFF = 2 * time.Second
+	DEFAULT_AUTOREG_MAX_BACKOFF     = 1 * time.Minute
+
+	DEFAULT_CERTIFICATE_RENEW_AT_FRACTION    = 2.0 / 3.0
+	DEFAULT_CERTIFICATE_RENEW_RETRY_INTERVAL = 1 * time.Minute
+
+	DEFAULT_PROFILE_NAME = "default"
+
+	DEFAULT_COLLECTOR_INTERVAL = 1 * time.Minute
+
+	DEFAULT_DEVICE_WATCHER_INTERVAL    = 30 * time.Second
+	DEFAULT_DEVICE_WATCHER_BUFFER_SIZE = 64
+
+	DEFAULT_WEBHOOK_DEDUPE_WINDOW       = 24 * time.Hour
+	DEFAULT_WEBHOOK_TIMESTAMP_TOLERANCE = 5 * time.Minute
+	DEFAULT_WEBHOOK_MAX_BODY_SIZE       = 1 << 20
+
+	WEBHOOK_SIGNATURE_HEADER = "X-Remoteit-Signature" // WEBHOOK_SIGNATURE_PREFIX and the hex HMAC-SHA256 of the body
+	WEBHOOK_SIGNATURE_PREFIX = "sha256="
+	WEBHOOK_SECRET_HEADER    = "X-Remoteit-Secret"
+
+	DEVICE_STATE_ACTIVE   = "active"
+	DEVICE_STATE_INACTIVE = "inactive"
+
+	// environment variables overriding the selected profile
+	ENV_CONFIG          = "REMOTEIT_CONFIG"
+	ENV_PROFILE         = "REMOTEIT_PROFILE"
+	ENV_API_URL         = "REMOTEIT_API_URL"
+	ENV_GRAPHQL_URL     = "REMOTEIT_GRAPHQL_URL"
+	ENV_RESTORE_URL     = "REMOTEIT_RESTORE_URL"
+	ENV_CERTIFICATE_URL = "REMOTEIT_CERTIFICATE_URL"
+	ENV_APIKEY          = "REMOTEIT_APIKEY"
+	ENV_USERNAME        = "REMOTEIT_USERNAME"
+	ENV_PASSWORD        = "REMOTEIT_PASSWORD"
+	ENV_AUTHHASH        = "REMOTEIT_AUTHHASH"
+	ENV_API_TIMEOUT     = "REMOTEIT_API_TIMEOUT"
+
+	// metrics reported through `api.Metrics`, the labels follow each name
+	METRIC_API_REQUEST_DURATION   = "remoteit_api_request_duration_seconds"   // group, method, status
+	METRIC_API_OPERATION_DURATION = "remoteit_api_operation_duration_seconds" // operation
+	METRIC_API_ERRORS             = "remoteit_api_errors_total"               // operation, code
+	METRIC_API_RETRIES            = "remoteit_api_retries_total"              // operation
+
+	// gauges exported by the `collector` package
+	METRIC_DEVICES                    = "remoteit_devices"                            // state, type, owner
+	METRIC_PROXY_CONNECTIONS          = "remoteit_proxy_connections"                  // device
+	METRIC_PROXY_CONNECTION_LIFE_LEFT = "remoteit_proxy_connection_life_left_seconds" // connection, device
+	METRIC_COLLECT_SUCCESS            = "remoteit_collect_success"
+	METRIC_COLLECT_TIMESTAMP          = "remoteit_collect_timestamp_seconds"
+
 	DEFAULT_ONLINE_CHECK_ENDPOINT       = "https://api.remote.it"
 	DEFAULT_ONLINE_CHECK_ENDPOINT_REPLY = "api.remote.it"
 
-	// FIXME:
-	// 1 - error code is a string with no standard
-	// 2 - is part of the `resp.Reason` instead of `resp.Error`,
-	//     `resp.Reason` must be a human readable form of the `resp.Error`
+	// API error codes are part of `resp.Reason`, `ParseAPIError` splits them out,
+	// reasons without a code are matched by the API_ERROR_CODE_REASON_* phrases
+	API_ERROR_CODE_USER_OR_PASSWORD_INVALID  = "0102"
+	API_ERROR_CODE_DEVICE_NOT_FOUND          = "0806"
+	API_ERROR_CODE_DUPLICATE_NAME            = "0807"
+	API_ERROR_CODE_SERVICE_NOT_FOUND_FOR_UID = "0861"
+
 	API_ERROR_CODE_REASON_DEVICE_NOT_FOUND          = "[0806]"
 	API_ERROR_CODE_REASON_DUPLICATE_NAME            = "[0807]"
 	API_ERROR_CODE_REASON_SERVICE_NOT_FOUND_FOR_UID = "[0861]"
@@ -0,0 +1,115 @@
+package contracts
+
+import (
+	"sort"
+	"time"
+
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+type DeviceEventType int
+
+const (
+	DeviceAdded DeviceEventType = iota
+	DeviceRemoved
+	DeviceRenamed
+	DeviceStateChanged
+	DeviceOwnerChanged
+)
+
+func (thisRef DeviceEventType) String() string {
+	switch thisRef {
+	case DeviceAdded:
+		return "added"
+	case DeviceRemoved:
+		return "removed"
+	case DeviceRenamed:
+		return "renamed"
+	case DeviceStateChanged:
+		return "state changed"
+	case DeviceOwnerChanged:
+		return "owner changed"
+	}
+
+	return "unknown"
+}
+
+type DeviceEvent struct {
+	Type     DeviceEventType
+	Device   Device // the last listed one for DeviceRemoved
+	Previous Device // empty for DeviceAdded
+	At       time.Time
+}
+
+// DeviceWatcherBackpressure is what the watcher does with an event once `BufferSize` of them
+// are waiting to be read.
+type DeviceWatcherBackpressure int
+
+const (
+	DeviceWatcherBlock      DeviceWatcherBackpressure = iota // wait for the reader, delaying the next poll
+	DeviceWatcherDropOldest                                  // drop the oldest waiting event
+)
+
+type DeviceWatcherOptions struct {
+	Interval     time.Duration // DEFAULT_DEVICE_WATCHER_INTERVAL when zero
+	BufferSize   int           // DEFAULT_DEVICE_WATCHER_BUFFER_SIZE when zero
+	Backpressure DeviceWatcherBackpressure
+
+	OnDropped func(event DeviceEvent)
+	OnError   func(err errorx.Error)
+}
+
+// DiffDevices returns the events turning `previous` into `current`, keyed by DeviceAddress.
+// A device both renamed and changing state gets one event of each, ordered by address with
+// the removed devices last.
+func DiffDevices(previous []Device, current []Device, at time.Time) []DeviceEvent {
+	previousByAddress := map[string]Device{}
+	for _, device := range previous {
+		previousByAddress[device.DeviceAddress] = device
+	}
+
+	currentByAddress := map[string]Device{}
+	for _, device := range current {
+		currentByAddress[device.DeviceAddress] = device
+	}
+
+	events := []DeviceEvent{}
+	for _, address := range sortedDeviceAddresses(currentByAddress) {
+		device := currentByAddress[address]
+
+		before, ok := previousByAddress[address]
+		if !ok {
+			events = append(events, DeviceEvent{Type: DeviceAdded, Device: device, At: at})
+			continue
+		}
+
+		if before.DeviceAlias != device.DeviceAlias {
+			events = append(events, DeviceEvent{Type: DeviceRenamed, Device: device, Previous: before, At: at})
+		}
+		if before.DeviceState != device.DeviceState {
+			events = append(events, DeviceEvent{Type: DeviceStateChanged, Device: device, Previous: before, At: at})
+		}
+		if before.OwnerUserName != device.OwnerUserName {
+			events = append(events, DeviceEvent{Type: DeviceOwnerChanged, Device: device, Previous: before, At: at})
+		}
+	}
+
+	for _, address := range sortedDeviceAddresses(previousByAddress) {
+		if _, ok := currentByAddress[address]; !ok {
+			device := previousByAddress[address]
+			events = append(events, DeviceEvent{Type: DeviceRemoved, Device: device, Previous: device, At: at})
+		}
+	}
+
+	return events
+}
+
+func sortedDeviceAddresses(devices map[string]Device) []string {
+	addresses := make([]string, 0, len(devices))
+	for address := range devices {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	return addresses
+}
@@ -5,6 +5,7 @@ type Device struct {
 	DeviceType    string `json:"devicetype,omitempty"`
 	DeviceAlias   string `json:"devicealias,omitempty"`
 	OwnerUserName string `json:"ownerusername,omitempty"`
+	DeviceState   string `json:"devicestate,omitempty"` // DEVICE_STATE_ACTIVE or DEVICE_STATE_INACTIVE
 	Scripting     bool   `json:"scripting,omitempty"`
 }
 
@@ -19,3 +20,20 @@ type DefinedDevice struct {
 	Name     string           `json:"name,omitempty"`
 	Services []DefinedService `json:"services,omitempty"`
 }
+
+// ListedDevice is a device of the GraphQL device listing.
+type ListedDevice struct {
+	ID       string
+	Name     string
+	State    string // DEVICE_STATE_ACTIVE or DEVICE_STATE_INACTIVE
+	Owner    string // email of the owner
+	Services []ListedService
+}
+
+type ListedService struct {
+	ID              string
+	Name            string
+	State           string
+	ApplicationType int
+	Connections     []string // IDs of the open proxy connections to the service
+}
@@ -0,0 +1,90 @@
+package contracts
+
+import (
+	"fmt"
+	"sort"
+
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+type ErrorCategory string
+
+const (
+	ErrorCategoryAutoReg     ErrorCategory = "autoreg"
+	ErrorCategoryAuth        ErrorCategory = "auth"
+	ErrorCategoryDevice      ErrorCategory = "device"
+	ErrorCategoryProxy       ErrorCategory = "proxy"
+	ErrorCategoryRestore     ErrorCategory = "restore"
+	ErrorCategoryService     ErrorCategory = "service"
+	ErrorCategoryClient      ErrorCategory = "client"
+	ErrorCategoryGraphQL     ErrorCategory = "graphql"
+	ErrorCategoryCertificate ErrorCategory = "certificate"
+	ErrorCategoryConfig      ErrorCategory = "config"
+	ErrorCategoryWebhook     ErrorCategory = "webhook"
+)
+
+type ErrorCatalogEntry struct {
+	Error    errorx.Error
+	Category ErrorCategory
+	Generic  bool // registered with `newErrorCode`, the code is used with `NewReasonError(code, reason)` to pass on API reasons
+}
+
+var errorCatalog = map[int]ErrorCatalogEntry{}
+
+// newError registers the error in the catalog, a code can only be registered once.
+func newError(category ErrorCategory, code int, message string) errorx.Error {
+	errx := NewError(code, message)
+	registerError(ErrorCatalogEntry{Error: errx, Category: category})
+	return errx
+}
+
+// newErrorCode registers a generic code, the message is only used by `ErrorByCode`.
+func newErrorCode(category ErrorCategory, code int, message string) int {
+	registerError(ErrorCatalogEntry{Error: NewError(code, message), Category: category, Generic: true})
+	return code
+}
+
+func registerError(entry ErrorCatalogEntry) {
+	if existing, ok := errorCatalog[entry.Error.Code()]; ok {
+		panic(fmt.Sprintf("contracts: error code %d is used by both %q and %q", entry.Error.Code(), existing.Error.Message(), entry.Error.Message()))
+	}
+
+	errorCatalog[entry.Error.Code()] = entry
+}
+
+// ErrorByCode returns the catalog error for `code`, for generic codes that is a
+// placeholder since the actual message comes from the API.
+func ErrorByCode(code int) (errorx.Error, bool) {
+	entry, ok := errorCatalog[code]
+	return entry.Error, ok
+}
+
+func ErrorCategoryByCode(code int) (ErrorCategory, bool) {
+	entry, ok := errorCatalog[code]
+	return entry.Category, ok
+}
+
+// ErrorCatalog returns every registered error ordered by code.
+func ErrorCatalog() []ErrorCatalogEntry {
+	entries := make([]ErrorCatalogEntry, 0, len(errorCatalog))
+	for _, entry := range errorCatalog {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Error.Code() < entries[j].Error.Code()
+	})
+
+	return entries
+}
+
+func ErrorsByCategory(category ErrorCategory) []errorx.Error {
+	errors := []errorx.Error{}
+	for _, entry := range ErrorCatalog() {
+		if entry.Category == category {
+			errors = append(errors, entry.Error)
+		}
+	}
+
+	return errors
+}
@@ -0,0 +1,100 @@
+package contracts
+
+import (
+	"fmt"
+
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+// Error is the `errorx.Error` returned by every SDK method. It matches any error with the
+// same code in `errors.Is`, so callers can compare against the catalog sentinels or an
+// `ErrorCode` even once it is wrapped.
+type Error struct {
+	CodePayload    int         `json:"code"`
+	MessagePayload string      `json:"message,omitempty"`
+	DataPayload    interface{} `json:"data,omitempty"`
+	Reason         string      `json:"reason,omitempty"`  // as sent by the API, if any
+	APICode        string      `json:"apiCode,omitempty"` // the bracketed code of the reason, if any
+	Cause          error       `json:"-"`
+}
+
+// ErrorCode lets `errors.Is` match by code alone, e.g. `errors.Is(err, ErrorCode(ErrAPI_Auth_Generic))`.
+type ErrorCode int
+
+func (c ErrorCode) Error() string {
+	return fmt.Sprintf("code: %d", int(c))
+}
+
+func NewError(code int, message string) *Error {
+	return &Error{
+		CodePayload:    code,
+		MessagePayload: message,
+	}
+}
+
+// NewReasonError carries the `reason` of an API response, it is both the message and the `Reason`.
+func NewReasonError(code int, reason string) *Error {
+	return &Error{
+		CodePayload:    code,
+		MessagePayload: reason,
+		Reason:         reason,
+	}
+}
+
+func NewErrorFromErr(code int, err error) *Error {
+	message := err.Error()
+	if errx, ok := err.(errorx.Error); ok {
+		message = errx.Message()
+	}
+
+	return &Error{
+		CodePayload:    code,
+		MessagePayload: message,
+		Cause:          err,
+	}
+}
+
+func (e *Error) WithReason(reason string) *Error {
+	copy := *e
+	copy.Reason = reason
+	return &copy
+}
+
+func (e *Error) Code() int {
+	return e.CodePayload
+}
+
+func (e *Error) Message() string {
+	return e.MessagePayload
+}
+
+func (e *Error) Data() interface{} {
+	return e.DataPayload
+}
+
+func (e *Error) String() string {
+	if e.DataPayload != nil {
+		return fmt.Sprintf("code: %d, message: %s, data: %v", e.CodePayload, e.MessagePayload, e.DataPayload)
+	}
+
+	return fmt.Sprintf("code: %d, message: %s", e.CodePayload, e.MessagePayload)
+}
+
+func (e *Error) Error() string {
+	return e.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+func (e *Error) Is(target error) bool {
+	switch t := target.(type) {
+	case ErrorCode:
+		return e.CodePayload == int(t)
+	case errorx.Error:
+		return e.CodePayload == t.Code()
+	}
+
+	return false
+}
@@ -1,101 +1,136 @@
 package contracts
 
-import errorx "github.com/remoteit/systemkit-errorx"
-
 var (
 	MFA_IS_ENABLED = "Sorry, your account has Two-Factor authentication and/or Google Auth configured. This version of the CLI does not support these features. Please either upgrade to a newer version of the CLI that supports these features or disable them in your account to use this version of the CLI. Please contact support@remote.it if you have any questions"
 
-	ErrAutoReg_Generic           = 300
-	ErrAutoreg_BICInvalid        = errorx.New(301, "AutoReg - RegistrationKey (Bulk Identification Code) is invalid")
-	ErrAutoreg_MaxAttempts       = errorx.New(302, "AutoReg - AutoRegisterIfNeeded: max nr of attemps was reached and they all failed")
-	ErrAutoreg_BICEmpty          = errorx.New(303, "AutoReg - Registration key (Bulk Identification Code) is empty")
-	ErrAutoreg_CantPrepRequest   = errorx.New(304, "AutoReg - Can't prep autoreg details")
-	ErrAutoreg_CantSendRequest   = errorx.New(305, "AutoReg - Can't send autoreg details")
-	ErrAutoreg_CantReadResponse  = errorx.New(306, "AutoReg - Can't read autoreg response")
-	ErrAutoreg_NoMatchingRegInfo = errorx.New(307, "AutoReg - No registration exists that matches the key")
-	ErrAutoreg_CantConvertPort   = errorx.New(308, "AutoReg - Can't convert port to integer")
-	ErrAutoreg_CantInstallAgent  = errorx.New(309, "AutoReg - Can't install agent")
-	ErrAutoreg_CantStartAgent    = errorx.New(310, "AutoReg - Can't start agent")
-
-	ErrAPI_Auth_Generic                 = 500
-	ErrAPI_Auth_Unknown                 = errorx.New(501, "Auth - Unknown error occurred")
-	ErrAPI_Auth_MFA_ENABLED             = errorx.New(502, MFA_IS_ENABLED)
-	ErrAPI_Auth_NoAuthHash              = errorx.New(503, "Auth - No auth hash returned")
-	ErrAPI_Auth_NoToken                 = errorx.New(504, "Auth - No token returned")
-	ErrAPI_Auth_CantPrepPasswordSignin  = errorx.New(505, "Auth - Can't prpe password signin")
-	ErrAPI_Auth_CantSendPasswordSignin  = errorx.New(506, "Auth - Can't send password signin")
-	ErrAPI_Auth_CantReadPasswordSignin  = errorx.New(507, "Auth - Can't read password signin reply")
-	ErrAPI_Auth_PasswordInvalid         = errorx.New(508, "Auth - Password is invalid")
-	ErrAPI_Auth_NoSuchUser              = errorx.New(509, "Auth - No such user")
-	ErrAPI_Auth_AuthHashCantPrepRequest = errorx.New(510, "Auth - AuthHash can't prep request")
-	ErrAPI_Auth_AuthHashCantSendRequest = errorx.New(511, "Auth - AuthHash can't send request")
-	ErrAPI_Auth_AuthHashCantReadResult  = errorx.New(512, "Auth - AuthHash can't read result")
-	ErrAPI_Auth_AuthHashInvalid         = errorx.New(513, "Auth - AuthHash invalid")
-
-	ErrAPI_AutoReg_Generic = 600
-
-	ErrAPI_DeviceList_Generic          = 700
-	ErrAPI_DeviceList_CantSendRequest  = errorx.New(701, "Device list - Can't send request")
-	ErrAPI_DeviceList_CantReadResponse = errorx.New(702, "Device list - Can't read response")
-
-	ErrAPI_Device_Generic          = 800
-	ErrAPI_Device_Unknown          = errorx.New(801, "Device - Unknown error occurred")
-	ErrAPI_Device_NoServiceFound   = errorx.New(802, "Device - No service found matching that UID")
-	ErrAPI_Device_CantPrepRequest  = errorx.New(803, "Device - Can't prepare request")
-	ErrAPI_Device_CantSendRequest  = errorx.New(804, "Device - Can't send request")
-	ErrAPI_Device_CantReadResponse = errorx.New(805, "Device - Can't read response")
-
-	ErrAPI_ProxyCreate_Generic          = 900
-	ErrAPI_ProxyCreate_Unknown          = errorx.New(901, "Create Proxy - Unknown error occurred")
-	ErrAPI_ProxyCreate_NoServiceFound   = errorx.New(902, "Create Proxy - No service found matching that UID")
-	ErrAPI_ProxyCreate_CantPrepRequest  = errorx.New(903, "Create Proxy - Can't prep request")
-	ErrAPI_ProxyCreate_CantSendRequest  = errorx.New(904, "Create Proxy - Can't send request")
-	ErrAPI_ProxyCreate_CantReadResponse = errorx.New(905, "Create Proxy - Can't read response")
-
-	ErrAPI_ProxyDelete_Generic          = 1000
-	ErrAPI_ProxyDelete_Unknown          = errorx.New(1001, "Delete Proxy - Unknown error occurred")
-	ErrAPI_ProxyDelete_CantPrepRequest  = errorx.New(1002, "Delete Proxy - Can't prep request")
-	ErrAPI_ProxyDelete_CantSendRequest  = errorx.New(1003, "Delete Proxy - Can't send request")
-	ErrAPI_ProxyDelete_CantReadResponse = errorx.New(1004, "Delete Proxy - Can't read response")
-
-	ErrAPI_RestoreClient_Generic           = 2000
-	ErrAPI_RestoreClient_Unknown           = errorx.New(2001, "Restore Client - Unknown error occurred")
-	ErrAPI_RestoreClient_DeviceActive      = errorx.New(2002, "Restore Client - The device state is active")
-	ErrAPI_RestoreClient_TokenNotSpecified = errorx.New(2003, "Restore Client - Token not specified or invalid")
-	ErrAPI_RestoreClient_DeviceNotExists   = errorx.New(2004, "Restore Client - Device does not exist or is not owned by the user")
-	ErrAPI_RestoreClient_CantPrepRequest   = errorx.New(2005, "Restore Client - Can't prep request")
-	ErrAPI_RestoreClient_CantSendRequest   = errorx.New(2006, "Restore Client - Can't send request")
-	ErrAPI_RestoreClient_CantReadResponse  = errorx.New(2007, "Restore Client - Can't read response")
-
-	ErrAPI_Service_Generic          = 3000
-	ErrAPI_Service_Unknown          = errorx.New(3001, "Service - Unknown error occurred")
-	ErrAPI_Service_NoServiceFound   = errorx.New(3002, "Service - No service found matching that UID")
-	ErrAPI_Service_CantPrepRequest  = errorx.New(3003, "Service - Can't prep request")
-	ErrAPI_Service_CantSendRequest  = errorx.New(3004, "Service - Can't send request")
-	ErrAPI_Service_CantReadResponse = errorx.New(3005, "Service - Can't read response")
-
-	ErrAPI_Helpers_Generic             = 4000
-	ErrAPI_Helpers_Unknown             = errorx.New(4001, "API Helpers - Unknown error occurred creating service")
-	ErrAPI_Helpers_CantCreateServiceID = errorx.New(4002, "API Helpers - Unknown error occurred creating a service UID")
-	ErrAPI_Helpers_NoUIDReturned       = errorx.New(4003, "API Helpers - No UID was returned by the API")
-	ErrAPI_Helpers_NoToken             = errorx.New(4004, "API Helpers - Missing authentication token")
-	ErrAPI_Helpers_ServiceUIDNotFound  = errorx.New(4005, "API Helpers - Service matching UID not found")
-	ErrAPI_Helpers_ServiceUIDMissing   = errorx.New(4006, "API Helpers - Service UID invalid or missing")
-	ErrAPI_Helpers_DeviceExists        = errorx.New(4007, "API Helpers - Service UID invalid or missing")
-
-	ErrAPI_Client_Generic           = 5000
-	ErrAPI_Client_CantCreateRequest = errorx.New(5001, "API Client - Error creating request")
-	ErrAPI_Client_CantSend          = errorx.New(5002, "API Client - Error sending request")
-	ErrAPI_Client_CantRead          = errorx.New(5003, "API Client - Error reading request")
-	ErrAPI_Client_Error             = errorx.New(5003, "API Client - Error communicating")
-
-	ErrAPI_GQL_Generic          = 6000
-	ErrAPI_GQL_CantPrepRequest  = errorx.New(6001, "GQL Client - Error creating request")
-	ErrAPI_GQL_CantSendRequest  = errorx.New(6002, "GQL Client - Error sending request")
-	ErrAPI_GQL_CantReadResponse = errorx.New(6003, "GQL Client - Error read response")
-	ErrAPI_GQL_NotAuthorized    = errorx.New(6004, "GQL Client - Not authorized")
-	ErrAPI_GQL_Error            = errorx.New(5003, "GQL Client - Error communicating")
-
-	ErrAPI_CertClient_Generic           = 7000
-	ErrAPI_CertClient_TokenNotSpecified = errorx.New(2003, "Certificate Client - Token not specified or invalid")
+	ErrAutoReg_Generic           = newErrorCode(ErrorCategoryAutoReg, 300, "AutoReg - Generic error")
+	ErrAutoreg_BICInvalid        = newError(ErrorCategoryAutoReg, 301, "AutoReg - RegistrationKey (Bulk Identification Code) is invalid")
+	ErrAutoreg_MaxAttempts       = newError(ErrorCategoryAutoReg, 302, "AutoReg - AutoRegisterIfNeeded: max nr of attemps was reached and they all failed")
+	ErrAutoreg_BICEmpty          = newError(ErrorCategoryAutoReg, 303, "AutoReg - Registration key (Bulk Identification Code) is empty")
+	ErrAutoreg_CantPrepRequest   = newError(ErrorCategoryAutoReg, 304, "AutoReg - Can't prep autoreg details")
+	ErrAutoreg_CantSendRequest   = newError(ErrorCategoryAutoReg, 305, "AutoReg - Can't send autoreg details")
+	ErrAutoreg_CantReadResponse  = newError(ErrorCategoryAutoReg, 306, "AutoReg - Can't read autoreg response")
+	ErrAutoreg_NoMatchingRegInfo = newError(ErrorCategoryAutoReg, 307, "AutoReg - No registration exists that matches the key")
+	ErrAutoreg_CantConvertPort   = newError(ErrorCategoryAutoReg, 308, "AutoReg - Can't convert port to integer")
+	ErrAutoreg_CantInstallAgent  = newError(ErrorCategoryAutoReg, 309, "AutoReg - Can't install agent")
+	ErrAutoreg_CantStartAgent    = newError(ErrorCategoryAutoReg, 310, "AutoReg - Can't start agent")
+	ErrAutoreg_CantLoadState     = newError(ErrorCategoryAutoReg, 311, "AutoReg - Can't load registration state")
+	ErrAutoreg_CantSaveState     = newError(ErrorCategoryAutoReg, 312, "AutoReg - Can't save registration state")
+	ErrAutoreg_NoHardwareID      = newError(ErrorCategoryAutoReg, 313, "AutoReg - Can't collect a machine ID or DMI product UUID to derive the hardware ID from")
+	ErrAutoreg_CantDownload      = newError(ErrorCategoryAutoReg, 314, "AutoReg - Can't download provisioning bundle")
+	ErrAutoreg_ChecksumMismatch  = newError(ErrorCategoryAutoReg, 315, "AutoReg - Provisioning bundle checksum does not match")
+	ErrAutoreg_CantConvertType   = newError(ErrorCategoryAutoReg, 316, "AutoReg - Can't convert service type to integer")
+
+	ErrAPI_Auth_Generic                 = newErrorCode(ErrorCategoryAuth, 500, "Auth - Generic error")
+	ErrAPI_Auth_Unknown                 = newError(ErrorCategoryAuth, 501, "Auth - Unknown error occurred")
+	ErrAPI_Auth_MFA_ENABLED             = newError(ErrorCategoryAuth, 502, MFA_IS_ENABLED)
+	ErrAPI_Auth_NoAuthHash              = newError(ErrorCategoryAuth, 503, "Auth - No auth hash returned")
+	ErrAPI_Auth_NoToken                 = newError(ErrorCategoryAuth, 504, "Auth - No token returned")
+	ErrAPI_Auth_CantPrepPasswordSignin  = newError(ErrorCategoryAuth, 505, "Auth - Can't prpe password signin")
+	ErrAPI_Auth_CantSendPasswordSignin  = newError(ErrorCategoryAuth, 506, "Auth - Can't send password signin")
+	ErrAPI_Auth_CantReadPasswordSignin  = newError(ErrorCategoryAuth, 507, "Auth - Can't read password signin reply")
+	ErrAPI_Auth_PasswordInvalid         = newError(ErrorCategoryAuth, 508, "Auth - Password is invalid")
+	ErrAPI_Auth_NoSuchUser              = newError(ErrorCategoryAuth, 509, "Auth - No such user")
+	ErrAPI_Auth_AuthHashCantPrepRequest = newError(ErrorCategoryAuth, 510, "Auth - AuthHash can't prep request")
+	ErrAPI_Auth_AuthHashCantSendRequest = newError(ErrorCategoryAuth, 511, "Auth - AuthHash can't send request")
+	ErrAPI_Auth_AuthHashCantReadResult  = newError(ErrorCategoryAuth, 512, "Auth - AuthHash can't read result")
+	ErrAPI_Auth_AuthHashInvalid         = newError(ErrorCategoryAuth, 513, "Auth - AuthHash invalid")
+
+	ErrAPI_AutoReg_Generic = newErrorCode(ErrorCategoryAutoReg, 600, "AutoReg API - Generic error")
+
+	ErrAPI_DeviceList_Generic          = newErrorCode(ErrorCategoryDevice, 700, "Device list - Generic error")
+	ErrAPI_DeviceList_CantSendRequest  = newError(ErrorCategoryDevice, 701, "Device list - Can't send request")
+	ErrAPI_DeviceList_CantReadResponse = newError(ErrorCategoryDevice, 702, "Device list - Can't read response")
+
+	ErrAPI_Device_Generic          = newErrorCode(ErrorCategoryDevice, 800, "Device - Generic error")
+	ErrAPI_Device_Unknown          = newError(ErrorCategoryDevice, 801, "Device - Unknown error occurred")
+	ErrAPI_Device_NoServiceFound   = newError(ErrorCategoryDevice, 802, "Device - No service found matching that UID")
+	ErrAPI_Device_CantPrepRequest  = newError(ErrorCategoryDevice, 803, "Device - Can't prepare request")
+	ErrAPI_Device_CantSendRequest  = newError(ErrorCategoryDevice, 804, "Device - Can't send request")
+	ErrAPI_Device_CantReadResponse = newError(ErrorCategoryDevice, 805, "Device - Can't read response")
+
+	ErrAPI_ProxyCreate_Generic          = newErrorCode(ErrorCategoryProxy, 900, "Create Proxy - Generic error")
+	ErrAPI_ProxyCreate_Unknown          = newError(ErrorCategoryProxy, 901, "Create Proxy - Unknown error occurred")
+	ErrAPI_ProxyCreate_NoServiceFound   = newError(ErrorCategoryProxy, 902, "Create Proxy - No service found matching that UID")
+	ErrAPI_ProxyCreate_CantPrepRequest  = newError(ErrorCategoryProxy, 903, "Create Proxy - Can't prep request")
+	ErrAPI_ProxyCreate_CantSendRequest  = newError(ErrorCategoryProxy, 904, "Create Proxy - Can't send request")
+	ErrAPI_ProxyCreate_CantReadResponse = newError(ErrorCategoryProxy, 905, "Create Proxy - Can't read response")
+
+	ErrAPI_ProxyDelete_Generic          = newErrorCode(ErrorCategoryProxy, 1000, "Delete Proxy - Generic error")
+	ErrAPI_ProxyDelete_Unknown          = newError(ErrorCategoryProxy, 1001, "Delete Proxy - Unknown error occurred")
+	ErrAPI_ProxyDelete_CantPrepRequest  = newError(ErrorCategoryProxy, 1002, "Delete Proxy - Can't prep request")
+	ErrAPI_ProxyDelete_CantSendRequest  = newError(ErrorCategoryProxy, 1003, "Delete Proxy - Can't send request")
+	ErrAPI_ProxyDelete_CantReadResponse = newError(ErrorCategoryProxy, 1004, "Delete Proxy - Can't read response")
+
+	ErrAPI_ProxyHealth_Generic    = newErrorCode(ErrorCategoryProxy, 1100, "Proxy Health - Generic error")
+	ErrAPI_ProxyHealth_NoEndpoint = newError(ErrorCategoryProxy, 1101, "Proxy Health - Connection has no endpoint to probe")
+	ErrAPI_ProxyHealth_BadMode    = newError(ErrorCategoryProxy, 1102, "Proxy Health - Unknown health check mode")
+
+	ErrAPI_RestoreClient_Generic           = newErrorCode(ErrorCategoryRestore, 2000, "Restore Client - Generic error")
+	ErrAPI_RestoreClient_Unknown           = newError(ErrorCategoryRestore, 2001, "Restore Client - Unknown error occurred")
+	ErrAPI_RestoreClient_DeviceActive      = newError(ErrorCategoryRestore, 2002, "Restore Client - The device state is active")
+	ErrAPI_RestoreClient_TokenNotSpecified = newError(ErrorCategoryRestore, 2003, "Restore Client - Token not specified or invalid")
+	ErrAPI_RestoreClient_DeviceNotExists   = newError(ErrorCategoryRestore, 2004, "Restore Client - Device does not exist or is not owned by the user")
+	ErrAPI_RestoreClient_CantPrepRequest   = newError(ErrorCategoryRestore, 2005, "Restore Client - Can't prep request")
+	ErrAPI_RestoreClient_CantSendRequest   = newError(ErrorCategoryRestore, 2006, "Restore Client - Can't send request")
+	ErrAPI_RestoreClient_CantReadResponse  = newError(ErrorCategoryRestore, 2007, "Restore Client - Can't read response")
+	ErrAPI_RestoreClient_InvalidConfig     = newError(ErrorCategoryRestore, 2008, "Restore Client - Restored config is invalid")
+	ErrAPI_RestoreClient_CantBackup        = newError(ErrorCategoryRestore, 2009, "Restore Client - Can't back up the agent config")
+	ErrAPI_RestoreClient_CantWriteConfig   = newError(ErrorCategoryRestore, 2010, "Restore Client - Can't write the agent config")
+	ErrAPI_RestoreClient_CantVerifyConfig  = newError(ErrorCategoryRestore, 2011, "Restore Client - Written agent config does not verify, rolled back")
+	ErrAPI_RestoreClient_CantRollback      = newError(ErrorCategoryRestore, 2012, "Restore Client - Can't roll back the agent config")
+
+	ErrAPI_Service_Generic          = newErrorCode(ErrorCategoryService, 3000, "Service - Generic error")
+	ErrAPI_Service_Unknown          = newError(ErrorCategoryService, 3001, "Service - Unknown error occurred")
+	ErrAPI_Service_NoServiceFound   = newError(ErrorCategoryService, 3002, "Service - No service found matching that UID")
+	ErrAPI_Service_CantPrepRequest  = newError(ErrorCategoryService, 3003, "Service - Can't prep request")
+	ErrAPI_Service_CantSendRequest  = newError(ErrorCategoryService, 3004, "Service - Can't send request")
+	ErrAPI_Service_CantReadResponse = newError(ErrorCategoryService, 3005, "Service - Can't read response")
+
+	ErrAPI_Helpers_Generic             = newErrorCode(ErrorCategoryService, 4000, "API Helpers - Generic error")
+	ErrAPI_Helpers_Unknown             = newError(ErrorCategoryService, 4001, "API Helpers - Unknown error occurred creating service")
+	ErrAPI_Helpers_CantCreateServiceID = newError(ErrorCategoryService, 4002, "API Helpers - Unknown error occurred creating a service UID")
+	ErrAPI_Helpers_NoUIDReturned       = newError(ErrorCategoryService, 4003, "API Helpers - No UID was returned by the API")
+	ErrAPI_Helpers_NoToken             = newError(ErrorCategoryService, 4004, "API Helpers - Missing authentication token")
+	ErrAPI_Helpers_ServiceUIDNotFound  = newError(ErrorCategoryService, 4005, "API Helpers - Service matching UID not found")
+	ErrAPI_Helpers_ServiceUIDMissing   = newError(ErrorCategoryService, 4006, "API Helpers - Service UID invalid or missing")
+	ErrAPI_Helpers_DeviceExists        = newError(ErrorCategoryService, 4007, "API Helpers - Service UID invalid or missing")
+
+	ErrAPI_Client_Generic           = newErrorCode(ErrorCategoryClient, 5000, "API Client - Generic error")
+	ErrAPI_Client_CantCreateRequest = newError(ErrorCategoryClient, 5001, "API Client - Error creating request")
+	ErrAPI_Client_CantSend          = newError(ErrorCategoryClient, 5002, "API Client - Error sending request")
+	ErrAPI_Client_CantRead          = newError(ErrorCategoryClient, 5003, "API Client - Error reading request")
+	ErrAPI_Client_Error             = newError(ErrorCategoryClient, 5004, "API Client - Error communicating")
+
+	ErrAPI_GQL_Generic          = newErrorCode(ErrorCategoryGraphQL, 6000, "GQL Client - Generic error")
+	ErrAPI_GQL_CantPrepRequest  = newError(ErrorCategoryGraphQL, 6001, "GQL Client - Error creating request")
+	ErrAPI_GQL_CantSendRequest  = newError(ErrorCategoryGraphQL, 6002, "GQL Client - Error sending request")
+	ErrAPI_GQL_CantReadResponse = newError(ErrorCategoryGraphQL, 6003, "GQL Client - Error read response")
+	ErrAPI_GQL_NotAuthorized    = newError(ErrorCategoryGraphQL, 6004, "GQL Client - Not authorized")
+	ErrAPI_GQL_Error            = newError(ErrorCategoryGraphQL, 6005, "GQL Client - Error communicating")
+
+	ErrAPI_CertClient_Generic           = newErrorCode(ErrorCategoryCertificate, 7000, "Certificate Client - Generic error")
+	ErrAPI_CertClient_TokenNotSpecified = newError(ErrorCategoryCertificate, 7001, "Certificate Client - Token not specified or invalid")
+	ErrAPI_CertClient_CantGenerateKey   = newError(ErrorCategoryCertificate, 7002, "Certificate Client - Can't generate private key")
+	ErrAPI_CertClient_CantCreateCSR     = newError(ErrorCategoryCertificate, 7003, "Certificate Client - Can't create certificate signing request")
+	ErrAPI_CertClient_KeyMismatch       = newError(ErrorCategoryCertificate, 7004, "Certificate Client - Certificate does not match the private key")
+	ErrAPI_CertClient_CantParseCert     = newError(ErrorCategoryCertificate, 7005, "Certificate Client - Can't parse certificate")
+	ErrAPI_CertClient_CantParseKey      = newError(ErrorCategoryCertificate, 7006, "Certificate Client - Can't parse private key")
+	ErrAPI_CertClient_CantStore         = newError(ErrorCategoryCertificate, 7007, "Certificate Client - Can't store certificate")
+	ErrAPI_CertClient_NoCertificate     = newError(ErrorCategoryCertificate, 7008, "Certificate Client - No valid certificate available")
+	ErrAPI_CertClient_CantPrepRequest   = newError(ErrorCategoryCertificate, 7009, "Certificate Client - Can't prep request")
+	ErrAPI_CertClient_CantSendRequest   = newError(ErrorCategoryCertificate, 7010, "Certificate Client - Can't send request")
+	ErrAPI_CertClient_CantReadResponse  = newError(ErrorCategoryCertificate, 7011, "Certificate Client - Can't read response")
+	ErrAPI_CertClient_Expired           = newError(ErrorCategoryCertificate, 7012, "Certificate Client - Certificate has expired")
+
+	ErrConfig_CantRead        = newError(ErrorCategoryConfig, 8001, "Config - Can't read config file")
+	ErrConfig_CantParse       = newError(ErrorCategoryConfig, 8002, "Config - Can't parse config file")
+	ErrConfig_ProfileNotFound = newError(ErrorCategoryConfig, 8003, "Config - Profile not found")
+	ErrConfig_InvalidProfile  = newError(ErrorCategoryConfig, 8004, "Config - Invalid profile")
+
+	ErrWebhook_CantReadBody     = newError(ErrorCategoryWebhook, 9001, "Webhook - Can't read notification body")
+	ErrWebhook_InvalidSignature = newError(ErrorCategoryWebhook, 9002, "Webhook - Notification signature or secret is invalid")
+	ErrWebhook_CantDecode       = newError(ErrorCategoryWebhook, 9003, "Webhook - Can't decode notification")
+	ErrWebhook_NoSecret         = newError(ErrorCategoryWebhook, 9004, "Webhook - No secret to verify notifications with")
+	ErrWebhook_StaleTimestamp   = newError(ErrorCategoryWebhook, 9005, "Webhook - Notification timestamp missing or out of tolerance")
 )
@@ -0,0 +1,54 @@
+package contracts
+
+import "time"
+
+// Profile is everything needed to reach one remote.it environment with one account.
+type Profile struct {
+	Name           string
+	APIURL         string
+	GraphQLURL     string
+	RestoreURL     string
+	CertificateURL string
+	APIKey         string
+	Username       string
+	Password       string
+	AuthHash       string // used instead of the password when set
+	Timeout        time.Duration
+	UserAgent      string
+	RateLimits     RateLimits // shared by every client of the profile
+}
+
+// ProfileConfig is a config file of named profiles.
+type ProfileConfig struct {
+	DefaultProfile string // DEFAULT_PROFILE_NAME when empty
+	Profiles       map[string]Profile
+}
+
+// WithDefaults fills every empty URL, the timeout and the user agent with the DEFAULT_* values.
+func (p Profile) WithDefaults() Profile {
+	if p.APIURL == "" {
+		p.APIURL = DEFAULT_API_URL
+	}
+	if p.GraphQLURL == "" {
+		p.GraphQLURL = DEFAULT_API_GRAPHQL_URL
+	}
+	if p.RestoreURL == "" {
+		p.RestoreURL = DEFAULT_API_RESTORE_URL
+	}
+	if p.CertificateURL == "" {
+		p.CertificateURL = DEFAULT_API_CERTIFICATE_URL
+	}
+	if p.Timeout <= 0 {
+		p.Timeout = DEFAULT_API_TIMEOUT
+	}
+	if p.UserAgent == "" {
+		p.UserAgent = DEFAULT_API_USER_AGENT
+	}
+
+	return p
+}
+
+// HasCredentials tells if the profile can log in.
+func (p Profile) HasCredentials() bool {
+	return p.Username != "" && (p.Password != "" || p.AuthHash != "")
+}
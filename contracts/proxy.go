@@ -1,5 +1,7 @@
 package contracts
 
+import "time"
+
 type CreateProxyResponse struct {
 	Status     string              `json:"status"`     // "true"
 	Reason     string              `json:"reason"`     // "..."
@@ -64,13 +66,16 @@ type ProxyConnectionInfo struct {
 	ProxyURL        string `json:"proxyURL,omitempty"`        // "proxy40.rt3.io:34168"
 	ReverseProxy    bool   `json:"reverseProxy,omitempty"`    // false
 
+	P2PConnected     bool `json:"p2pConnected,omitempty"`     // true
+	ServiceConnected bool `json:"serviceConnected,omitempty"` // true
+
+	LifeLeft int `json:"lifeLeft,omitempty"` // 86400 - seconds until the proxy closes
+
 	//
 	// Ignore for now
 	//
 	// Initiator          string `json:"initiator,omitempty"`          // "nicolae@remote.it"
 	// FilteredIP         string `json:"filteredIP,omitempty"`         // "latching"
-	// P2PConnected       bool   `json:"p2pConnected,omitempty"`       // true
-	// ServiceConnected   bool   `json:"serviceConnected,omitempty"`   // true
 	// DeviceAddress      string `json:"deviceaddress,omitempty"`      // "80:00:00:00:01:00:40:C5" - target UID
 	// Status             string `json:"status,omitempty"`             // "running"
 	// ProxyExpirationSec int    `json:"proxyExpirationSec,omitempty"` // 28800 - ???????
@@ -78,7 +83,6 @@ type ProxyConnectionInfo struct {
 	// PeerReqEP          bool   `json:"peerReqEP,omitempty"`          // "18.184.71.109:62292" - ??????
 	// PeerEP             bool   `json:"peerEP,omitempty"`             // "18.184.71.109:62292" - ??????
 	// LatchedIP          bool   `json:"latchedIP,omitempty"`          // "0.0.0.0" - ??????
-	// LifeLeft           bool   `json:"lifeLeft,omitempty"`           // 86400 - ??????
 	// IdleLeft           bool   `json:"idleLeft,omitempty"`           // 900 - ??????
 	// Requested          string `json:"requested,omitempty"`          // "2/12/2020T10:18 AM"
 	// RequestedAt        string `json:"requestedAt,omitempty"`        // "2021-01-15T23:20:00+00:00"
@@ -86,6 +90,7 @@ type ProxyConnectionInfo struct {
 
 type DeleteProxyResponse struct {
 	Status string `json:"status"` // "true"
+	Reason string `json:"reason"` // "..."
 }
 
 type DeleteProxyRequest struct {
@@ -110,3 +115,27 @@ type CreateProxyRequest struct {
 	Concurrent    bool   `json:"concurrent,omitempty"`    // FIXME: will this be remvoed in the future ?
 	ProxyType     string `json:"proxyType,omitempty"`     // proxyType=port
 }
+
+type ProxyHealthCheckMode int
+
+const (
+	ProxyHealthCheckTCP ProxyHealthCheckMode = iota
+	ProxyHealthCheckHTTP
+	ProxyHealthCheckTLS
+)
+
+type ProxyHealthCheckRequest struct {
+	Mode          ProxyHealthCheckMode
+	Timeout       time.Duration // DEFAULT_PROXY_HEALTH_CHECK_TIMEOUT when zero
+	HTTPPath      string        // "/" when empty, used by ProxyHealthCheckHTTP only
+	TLSServerName string        // the proxy server name when empty, used by ProxyHealthCheckTLS only
+	TLSSkipVerify bool          // skips certificate verification for self signed targets
+}
+
+type ProxyHealthCheckResponse struct {
+	Endpoint       string        // "proxy40.rt3.io:34168"
+	Reachable      bool          // true when the remote service answered
+	Duration       time.Duration // how long the probe took
+	HTTPStatusCode int           // only set by ProxyHealthCheckHTTP
+	Reason         string        // why the probe failed, empty when Reachable
+}
@@ -0,0 +1,31 @@
+package contracts
+
+// RateLimitGroup is a set of API endpoints sharing one client-side rate limit.
+type RateLimitGroup string
+
+const (
+	RateLimitGroupAuth    RateLimitGroup = "auth"    // the logins
+	RateLimitGroupDevice  RateLimitGroup = "device"  // device and service management, bulk registration
+	RateLimitGroupConnect RateLimitGroup = "connect" // proxy connections
+	RateLimitGroupGraphQL RateLimitGroup = "graphql" // every GraphQL query
+	RateLimitGroupOther   RateLimitGroup = "other"   // restore, certificates and anything else
+)
+
+// RateLimitGroups are all the groups.
+var RateLimitGroups = []RateLimitGroup{
+	RateLimitGroupAuth,
+	RateLimitGroupDevice,
+	RateLimitGroupConnect,
+	RateLimitGroupGraphQL,
+	RateLimitGroupOther,
+}
+
+// RateLimit is a token bucket: `Burst` requests at once, refilled at `RequestsPerSecond`.
+// A zero `RequestsPerSecond` means no limit and a zero `Burst` means 1.
+type RateLimit struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	Burst             int     `json:"burst"`
+}
+
+// RateLimits are the limits by group, a group without one is not limited.
+type RateLimits map[RateLimitGroup]RateLimit
@@ -0,0 +1,101 @@
+package contracts
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Redacted replaces the value of every redacted field.
+const Redacted = "REDACTED"
+
+// RedactJSON replaces the non empty string values of `fields`, matched case insensitively at any
+// depth, and re-encodes the body with sorted keys. Other bodies are returned as they are.
+func RedactJSON(body []byte, fields []string) string {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return string(body)
+	}
+
+	redacted, err := json.Marshal(redactValue(value, fields))
+	if err != nil {
+		return string(body)
+	}
+
+	return string(redacted)
+}
+
+// The endpoints taking secrets in their path, and how many of the segments after the prefix
+// are secret. The longer prefixes go first.
+var redactedPathSegments = []struct {
+	prefix   string
+	segments int
+}{
+	{"/bulk/registration/device/friendly/configuration/", 1}, // registration key
+	{"/device/enablement/", 1},                               // registration key
+	{"/project/provisioning/download/", 1},                   // registration key
+	{"/project/provisioning/", 1},                            // registration key
+	{"/device/address/", 2},                                  // project key and project secret
+}
+
+// RedactPath replaces the secrets the API takes in the URL path, the registration keys and the
+// project key and secret. The base path of the API and the query are kept.
+func RedactPath(path string) string {
+	query := ""
+	if i := strings.Index(path, "?"); i >= 0 {
+		path, query = path[:i], path[i:]
+	}
+
+	for _, redacted := range redactedPathSegments {
+		i := strings.Index(path, redacted.prefix)
+		if i < 0 {
+			continue
+		}
+
+		start := i + len(redacted.prefix)
+		segments := strings.SplitN(path[start:], "/", redacted.segments+1)
+		for j := 0; j < len(segments) && j < redacted.segments; j++ {
+			if segments[j] != "" {
+				segments[j] = Redacted
+			}
+		}
+
+		return path[:start] + strings.Join(segments, "/") + query
+	}
+
+	return path + query
+}
+
+func redactValue(value interface{}, fields []string) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if isRedactedField(key, fields) {
+				if _, ok := field.(string); ok && field != "" {
+					value[key] = Redacted
+				}
+				continue
+			}
+			value[key] = redactValue(field, fields)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = redactValue(item, fields)
+		}
+	}
+
+	return value
+}
+
+func isRedactedField(key string, fields []string) bool {
+	for _, field := range fields {
+		if strings.EqualFold(key, field) {
+			return true
+		}
+	}
+
+	return false
+}
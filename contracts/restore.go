@@ -0,0 +1,142 @@
+package contracts
+
+import (
+	"encoding/json"
+	"fmt"
+
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+type RestoreConfig struct {
+	DeviceUID    string
+	DeviceSecret string
+	DeviceName   string
+	HardwareID   string
+	Services     []Service
+	Names        map[string]string // UID -> name, for the device and every service
+	Raw          []byte            // the config exactly as returned by the API
+}
+
+// SAMPLE
+//
+//	{
+//	    "device": {
+//	        "uid": "80:00:00:00:01:00:40:C5",
+//	        "secret": "...",
+//	        "name": "raspberrypi",
+//	        "hardwareId": "55eb0e08ddd14f8d8752a982e18bd4aa"
+//	    },
+//	    "services": [
+//	        {
+//	            "uid": "80:00:00:00:01:00:40:C6",
+//	            "secret": "...",
+//	            "name": "ssh",
+//	            "type": 28,
+//	            "hostname": "127.0.0.1",
+//	            "port": 22,
+//	            "enabled": true
+//	        }
+//	    ]
+//	}
+type restoreConfigPayload struct {
+	Device struct {
+		UID        string `json:"uid"`
+		Secret     string `json:"secret"`
+		Name       string `json:"name"`
+		HardwareID string `json:"hardwareId"`
+	} `json:"device"`
+	Services []struct {
+		UID      string `json:"uid"`
+		Secret   string `json:"secret"`
+		Name     string `json:"name"`
+		Type     int    `json:"type"`
+		Hostname string `json:"hostname"`
+		Port     int    `json:"port"`
+		Enabled  *bool  `json:"enabled"`
+	} `json:"services"`
+}
+
+// ParseRestoreConfig reads a restore reply, a config failing Validate is returned along with
+// the error so `Raw` and what could be parsed tell what is wrong.
+func ParseRestoreConfig(raw []byte) (RestoreConfig, errorx.Error) {
+	var payload restoreConfigPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return RestoreConfig{}, NewErrorFromErr(ErrAPI_RestoreClient_CantReadResponse.Code(), err)
+	}
+
+	config := RestoreConfig{
+		DeviceUID:    payload.Device.UID,
+		DeviceSecret: payload.Device.Secret,
+		DeviceName:   payload.Device.Name,
+		HardwareID:   payload.Device.HardwareID,
+		Services:     []Service{},
+		Names:        map[string]string{},
+		Raw:          raw,
+	}
+
+	if config.DeviceUID != "" {
+		config.Names[config.DeviceUID] = config.DeviceName
+	}
+
+	for _, service := range payload.Services {
+		overload := 0
+		if service.Type == BulkServiceID {
+			overload = MultiPortServiceID
+		}
+
+		config.Services = append(config.Services, Service{
+			Disabled:   service.Enabled != nil && !*service.Enabled,
+			HardwareID: config.HardwareID,
+			Hostname:   service.Hostname,
+			Overload:   overload,
+			Port:       service.Port,
+			Secret:     service.Secret,
+			Type:       service.Type,
+			UID:        service.UID,
+		})
+		config.Names[service.UID] = service.Name
+	}
+
+	if errx := config.Validate(); errx != nil {
+		return config, errx
+	}
+
+	return config, nil
+}
+
+func (c RestoreConfig) Validate() errorx.Error {
+	if c.DeviceUID == "" {
+		return NewError(ErrAPI_RestoreClient_InvalidConfig.Code(), "device UID is missing")
+	}
+	if c.DeviceSecret == "" {
+		return NewError(ErrAPI_RestoreClient_InvalidConfig.Code(), "device secret is missing")
+	}
+
+	seen := map[string]bool{c.DeviceUID: true}
+	for i, service := range c.Services {
+		if service.UID == "" {
+			return NewError(ErrAPI_RestoreClient_InvalidConfig.Code(), fmt.Sprintf("service %d has no UID", i))
+		}
+		if seen[service.UID] {
+			return NewError(ErrAPI_RestoreClient_InvalidConfig.Code(), fmt.Sprintf("UID %s is used more than once", service.UID))
+		}
+		if service.Secret == "" {
+			return NewError(ErrAPI_RestoreClient_InvalidConfig.Code(), fmt.Sprintf("service %s has no secret", service.UID))
+		}
+		if service.Port < 0 || service.Port > 65535 {
+			return NewError(ErrAPI_RestoreClient_InvalidConfig.Code(), fmt.Sprintf("service %s has an invalid port %d", service.UID, service.Port))
+		}
+
+		seen[service.UID] = true
+	}
+
+	return nil
+}
+
+type RestoreApplyResult struct {
+	Config         RestoreConfig
+	DryRun         bool
+	Changed        bool
+	Diff           string // line diff between the current and the restored agent config, secrets masked
+	BackupLocation string // empty when there was nothing to back up or on a dry run
+}
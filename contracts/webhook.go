@@ -0,0 +1,75 @@
+package contracts
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+type WebhookEventType string
+
+const (
+	WebhookDeviceState      WebhookEventType = "DEVICE_STATE"
+	WebhookDeviceConnect    WebhookEventType = "DEVICE_CONNECT"
+	WebhookDeviceDisconnect WebhookEventType = "DEVICE_DISCONNECT"
+)
+
+// WebhookEvent is the envelope of every notification, `Data` depends on `Type`.
+type WebhookEvent struct {
+	ID        string           `json:"id"`
+	Type      WebhookEventType `json:"type"`
+	Timestamp time.Time        `json:"timestamp"`
+	Data      json.RawMessage  `json:"data,omitempty"`
+}
+
+// DeviceStateEvent is the data of a WebhookDeviceState notification.
+type DeviceStateEvent struct {
+	WebhookEvent `json:"-"`
+
+	DeviceAddress string `json:"deviceaddress"`
+	DeviceAlias   string `json:"devicealias,omitempty"`
+	State         string `json:"state"` // DEVICE_STATE_ACTIVE or DEVICE_STATE_INACTIVE
+}
+
+// ConnectionEvent is the data of a WebhookDeviceConnect or WebhookDeviceDisconnect notification.
+type ConnectionEvent struct {
+	WebhookEvent `json:"-"`
+
+	DeviceAddress string `json:"deviceaddress"`
+	DeviceAlias   string `json:"devicealias,omitempty"`
+	ConnectionID  string `json:"connectionid,omitempty"`
+	UserName      string `json:"username,omitempty"` // who connected
+	Connected     bool   `json:"-"`                  // false for WebhookDeviceDisconnect
+}
+
+type WebhookReceiverOptions struct {
+	// Secret is the HMAC-SHA256 key of the WEBHOOK_SIGNATURE_HEADER, or the plain value of the
+	// WEBHOOK_SECRET_HEADER. Every notification is refused when empty, unless Insecure is set.
+	Secret string
+	// AllowSecretHeader accepts the WEBHOOK_SECRET_HEADER of the senders that can't sign, it
+	// doesn't cover the body so anyone seeing one notification can send others.
+	AllowSecretHeader bool
+	// Insecure accepts every notification when Secret is empty, only use it in tests.
+	Insecure bool
+
+	// TimestampTolerance is how far the timestamp of a notification may be from now, older
+	// ones are refused as replays. The DedupeWindow is raised to twice the tolerance so that
+	// a notification can't be replayed once its ID is forgotten.
+	TimestampTolerance time.Duration // DEFAULT_WEBHOOK_TIMESTAMP_TOLERANCE when zero
+	DedupeWindow       time.Duration // DEFAULT_WEBHOOK_DEDUPE_WINDOW when zero
+	MaxBodySize        int64         // DEFAULT_WEBHOOK_MAX_BODY_SIZE when zero
+
+	OnError func(err errorx.Error)
+}
+
+// WebhookSignature is the WEBHOOK_SIGNATURE_HEADER value of `body` signed with `secret`.
+func WebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return WEBHOOK_SIGNATURE_PREFIX + hex.EncodeToString(mac.Sum(nil))
+}
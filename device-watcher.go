@@ -0,0 +1,212 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+// DeviceWatcher polls `Device.ListAll` and emits the differences between successive listings
+// on `Events`. The API has no device event stream, polling is the only source.
+type DeviceWatcher interface {
+	Start() errorx.Error
+	Stop()
+	PollNow() errorx.Error
+
+	// Events is the channel of the running watcher, it is closed by `Stop`
+	Events() <-chan apiContracts.DeviceEvent
+	Devices() []apiContracts.Device
+}
+
+func NewDeviceWatcher(devices Device, options apiContracts.DeviceWatcherOptions) DeviceWatcher {
+	if options.Interval <= 0 {
+		options.Interval = apiContracts.DEFAULT_DEVICE_WATCHER_INTERVAL
+	}
+	if options.BufferSize <= 0 {
+		options.BufferSize = apiContracts.DEFAULT_DEVICE_WATCHER_BUFFER_SIZE
+	}
+
+	return &deviceWatcher{
+		devices: devices,
+		options: options,
+	}
+}
+
+type deviceWatcher struct {
+	devices Device
+	options apiContracts.DeviceWatcherOptions
+
+	mutex    sync.RWMutex
+	snapshot []apiContracts.Device
+
+	// one poll at a time, from `run` or `PollNow`
+	pollMutex sync.Mutex
+
+	stopMutex sync.Mutex
+	events    chan apiContracts.DeviceEvent
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// Start takes the first listing, the devices in it are not reported as added, and then keeps
+// polling in the background until `Stop` is called.
+func (thisRef *deviceWatcher) Start() errorx.Error {
+	thisRef.stopMutex.Lock()
+	defer thisRef.stopMutex.Unlock()
+
+	if thisRef.stop != nil {
+		return nil
+	}
+
+	response, errx := thisRef.devices.ListAll()
+	if errx != nil {
+		return errx
+	}
+	thisRef.swap(response.Devices)
+
+	thisRef.events = make(chan apiContracts.DeviceEvent, thisRef.options.BufferSize)
+	thisRef.stop = make(chan struct{})
+	thisRef.done = make(chan struct{})
+	go thisRef.run(thisRef.events, thisRef.stop, thisRef.done)
+
+	return nil
+}
+
+func (thisRef *deviceWatcher) Stop() {
+	thisRef.stopMutex.Lock()
+	defer thisRef.stopMutex.Unlock()
+
+	if thisRef.stop == nil {
+		return
+	}
+
+	close(thisRef.stop)
+	<-thisRef.done
+
+	// a `PollNow` still emitting holds the poll mutex
+	thisRef.pollMutex.Lock()
+	close(thisRef.events)
+	thisRef.pollMutex.Unlock()
+
+	thisRef.stop = nil
+	thisRef.done = nil
+}
+
+func (thisRef *deviceWatcher) Events() <-chan apiContracts.DeviceEvent {
+	thisRef.stopMutex.Lock()
+	defer thisRef.stopMutex.Unlock()
+
+	return thisRef.events
+}
+
+// PollNow lists the devices right away and emits the differences, it waits for the reader
+// like the background polls do. It only emits while the watcher is running.
+func (thisRef *deviceWatcher) PollNow() errorx.Error {
+	thisRef.stopMutex.Lock()
+	events, stop := thisRef.events, thisRef.stop
+	thisRef.stopMutex.Unlock()
+
+	if stop == nil {
+		return nil
+	}
+
+	return thisRef.poll(events, stop)
+}
+
+// Devices is the last listing.
+func (thisRef *deviceWatcher) Devices() []apiContracts.Device {
+	thisRef.mutex.RLock()
+	defer thisRef.mutex.RUnlock()
+
+	return append([]apiContracts.Device{}, thisRef.snapshot...)
+}
+
+func (thisRef *deviceWatcher) run(events chan apiContracts.DeviceEvent, stop chan struct{}, done chan struct{}) {
+	defer close(done)
+
+	for {
+		timer := time.NewTimer(thisRef.options.Interval)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if errx := thisRef.poll(events, stop); errx != nil {
+			thisRef.reportError(errx)
+		}
+	}
+}
+
+// poll keeps the last listing when the API fails, the next poll reports what changed since.
+func (thisRef *deviceWatcher) poll(events chan apiContracts.DeviceEvent, stop chan struct{}) errorx.Error {
+	thisRef.pollMutex.Lock()
+	defer thisRef.pollMutex.Unlock()
+
+	select {
+	case <-stop:
+		return nil
+	default:
+	}
+
+	response, errx := thisRef.devices.ListAll()
+	if errx != nil {
+		return errx
+	}
+
+	previous := thisRef.Devices()
+	thisRef.swap(response.Devices)
+
+	for _, event := range apiContracts.DiffDevices(previous, response.Devices, time.Now()) {
+		if !thisRef.emit(events, stop, event) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// emit returns false once the watcher is stopping.
+func (thisRef *deviceWatcher) emit(events chan apiContracts.DeviceEvent, stop chan struct{}, event apiContracts.DeviceEvent) bool {
+	if thisRef.options.Backpressure == apiContracts.DeviceWatcherDropOldest {
+		for {
+			select {
+			case events <- event:
+				return true
+			default:
+			}
+
+			// the reader may have emptied the buffer meanwhile, then nothing is dropped
+			select {
+			case dropped := <-events:
+				if thisRef.options.OnDropped != nil {
+					thisRef.options.OnDropped(dropped)
+				}
+			default:
+			}
+		}
+	}
+
+	select {
+	case events <- event:
+		return true
+	case <-stop:
+		return false
+	}
+}
+
+func (thisRef *deviceWatcher) swap(devices []apiContracts.Device) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	thisRef.snapshot = append([]apiContracts.Device{}, devices...)
+}
+
+func (thisRef *deviceWatcher) reportError(errx errorx.Error) {
+	if thisRef.options.OnError != nil {
+		thisRef.options.OnError(errx)
+	}
+}
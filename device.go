@@ -3,7 +3,6 @@ package api
 import (
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	apiContracts "github.com/remoteit/sdk-go/contracts"
 	errorx "github.com/remoteit/systemkit-errorx"
@@ -25,7 +24,11 @@ type device struct {
 	apiClient Client
 }
 
-func (thisRef device) Unregister(uid string) errorx.Error {
+func (thisRef device) Unregister(uid string) (errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "Device.Unregister")
+	defer op.end(&errx)
+
 	type request struct{}
 	data := request{}
 	body, err := json.Marshal(data)
@@ -48,21 +51,17 @@ func (thisRef device) Unregister(uid string) errorx.Error {
 	}
 
 	if resp.Status != apiContracts.API_ERROR_CODE_STATUS_TRUE {
-		if strings.Contains(resp.Reason, apiContracts.API_ERROR_CODE_REASON_SERVICE_NOT_FOUND_FOR_UID) {
-			return apiContracts.ErrAPI_Device_NoServiceFound
-		}
-
-		if !(len(strings.TrimSpace(resp.Reason)) <= 0) {
-			return errorx.New(apiContracts.ErrAPI_Device_Generic, resp.Reason)
-		}
-
-		return apiContracts.ErrAPI_Device_Unknown
+		return apiContracts.ParseAPIError(resp.Reason).ToError(deviceUnregisterAPIErrors, apiContracts.ErrAPI_Device_Generic, apiContracts.ErrAPI_Device_Unknown)
 	}
 
 	return nil
 }
 
-func (thisRef device) Transfer(uid string, destinationAccount string) errorx.Error {
+func (thisRef device) Transfer(uid string, destinationAccount string) (errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "Device.Transfer")
+	defer op.end(&errx)
+
 	type request struct {
 		User string `json:"user"`
 	}
@@ -89,17 +88,17 @@ func (thisRef device) Transfer(uid string, destinationAccount string) errorx.Err
 	}
 
 	if resp.Status != apiContracts.API_ERROR_CODE_STATUS_TRUE {
-		if !(len(strings.TrimSpace(resp.Reason)) <= 0) {
-			return errorx.New(apiContracts.ErrAPI_Device_Generic, resp.Reason)
-		}
-
-		return apiContracts.ErrAPI_Device_Unknown
+		return apiContracts.ParseAPIError(resp.Reason).ToError(deviceTransferAPIErrors, apiContracts.ErrAPI_Device_Generic, apiContracts.ErrAPI_Device_Unknown)
 	}
 
 	return nil
 }
 
-func (thisRef device) ListAll() (apiContracts.DeviceListAllResponse, errorx.Error) {
+func (thisRef device) ListAll() (_ apiContracts.DeviceListAllResponse, errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "Device.ListAll")
+	defer op.end(&errx)
+
 	raw, err := thisRef.apiClient.Get("/device/list/all?cache=false")
 	if err != nil {
 		return apiContracts.DeviceListAllResponse{}, apiContracts.ErrAPI_DeviceList_CantSendRequest
@@ -111,7 +110,7 @@ func (thisRef device) ListAll() (apiContracts.DeviceListAllResponse, errorx.Erro
 	}
 
 	if response.Status != apiContracts.API_ERROR_CODE_STATUS_TRUE {
-		return apiContracts.DeviceListAllResponse{}, errorx.New(apiContracts.ErrAPI_DeviceList_Generic, response.Reason)
+		return apiContracts.DeviceListAllResponse{}, apiContracts.ParseAPIError(response.Reason).ToError(deviceListAPIErrors, apiContracts.ErrAPI_DeviceList_Generic, nil)
 	}
 
 	return response, nil
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+// HardwareIdentityCollector gathers the device details `AutoRegistration.SendDeviceInfo` needs.
+type HardwareIdentityCollector interface {
+	Collect(version string) (apiContracts.DeviceInfo, errorx.Error)
+}
+
+// NewLinuxHardwareIdentityCollector reads `/etc/machine-id`, `/sys/class/dmi/id`, `/proc/cpuinfo`,
+// `/sys/class/net` and `/etc/os-release` relative to `rootPath`, an empty `rootPath` means `/`.
+// The hardware ID only comes from the machine ID, or the DMI product UUID or serial without
+// one, so that a new network card or a CPU of the same model doesn't change it.
+func NewLinuxHardwareIdentityCollector(rootPath string) HardwareIdentityCollector {
+	if rootPath == "" {
+		rootPath = "/"
+	}
+
+	return &linuxHardwareIdentityCollector{
+		rootPath: rootPath,
+	}
+}
+
+type linuxHardwareIdentityCollector struct {
+	rootPath string
+}
+
+const emptyMACAddress = "00:00:00:00:00:00"
+
+func (thisRef linuxHardwareIdentityCollector) Collect(version string) (apiContracts.DeviceInfo, errorx.Error) {
+	machineID := thisRef.machineID()
+
+	identity := machineID
+	if identity == "" {
+		identity = thisRef.dmiID()
+	}
+	if identity == "" {
+		return apiContracts.DeviceInfo{}, apiContracts.ErrAutoreg_NoHardwareID
+	}
+
+	return apiContracts.DeviceInfo{
+		HardwareID:     hardwareIDFrom(identity),
+		MachineID:      machineID,
+		CPUID:          thisRef.cpuID(),
+		MACAddress:     thisRef.macAddress(),
+		Version:        version,
+		PlatformOSName: thisRef.osName(),
+	}, nil
+}
+
+func (thisRef linuxHardwareIdentityCollector) path(elem ...string) string {
+	return filepath.Join(append([]string{thisRef.rootPath}, elem...)...)
+}
+
+func (thisRef linuxHardwareIdentityCollector) readTrimmed(elem ...string) string {
+	data, err := ioutil.ReadFile(thisRef.path(elem...))
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(data))
+}
+
+func (thisRef linuxHardwareIdentityCollector) machineID() string {
+	if machineID := thisRef.readTrimmed("etc", "machine-id"); machineID != "" {
+		return machineID
+	}
+
+	// older distributions only have the dbus copy
+	return thisRef.readTrimmed("var", "lib", "dbus", "machine-id")
+}
+
+// The DMI values of boards the vendor didn't fill in, shared by every such board.
+var dmiPlaceholders = []string{
+	"", "none", "not specified", "not applicable", "default string", "system serial number",
+	"to be filled by o.e.m.", "00000000-0000-0000-0000-000000000000", "ffffffff-ffff-ffff-ffff-ffffffffffff",
+}
+
+// dmiID is the product UUID, or the product serial, of the board.
+func (thisRef linuxHardwareIdentityCollector) dmiID() string {
+	for _, name := range []string{"product_uuid", "product_serial"} {
+		value := thisRef.readTrimmed("sys", "class", "dmi", "id", name)
+		if !isDMIPlaceholder(value) {
+			return strings.ToLower(value)
+		}
+	}
+
+	return ""
+}
+
+func isDMIPlaceholder(value string) bool {
+	for _, placeholder := range dmiPlaceholders {
+		if strings.EqualFold(value, placeholder) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (thisRef linuxHardwareIdentityCollector) cpuID() string {
+	data, err := ioutil.ReadFile(thisRef.path("proc", "cpuinfo"))
+	if err != nil {
+		return ""
+	}
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		key := strings.ToLower(strings.TrimSpace(parts[0]))
+		if _, ok := values[key]; !ok {
+			values[key] = strings.TrimSpace(parts[1])
+		}
+	}
+
+	// ARM boards expose a real serial, everything else gets the first CPU model
+	for _, key := range []string{"serial", "model name", "hardware", "cpu model", "processor"} {
+		if value := values[key]; value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func (thisRef linuxHardwareIdentityCollector) macAddress() string {
+	entries, err := ioutil.ReadDir(thisRef.path("sys", "class", "net"))
+	if err != nil {
+		return ""
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		if entry.Name() != "lo" {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	// physical interfaces have a `device` link, virtual ones (docker, veth, bridges) do not
+	var fallback string
+	for _, name := range names {
+		address := strings.ToLower(thisRef.readTrimmed("sys", "class", "net", name, "address"))
+		if address == "" || address == emptyMACAddress {
+			continue
+		}
+
+		if _, err := ioutil.ReadDir(thisRef.path("sys", "class", "net", name, "device")); err == nil {
+			return address
+		}
+
+		if fallback == "" {
+			fallback = address
+		}
+	}
+
+	return fallback
+}
+
+func (thisRef linuxHardwareIdentityCollector) osName() string {
+	data, err := ioutil.ReadFile(thisRef.path("etc", "os-release"))
+	if err != nil {
+		data, err = ioutil.ReadFile(thisRef.path("usr", "lib", "os-release"))
+		if err != nil {
+			return "linux"
+		}
+	}
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		parts := strings.SplitN(strings.TrimSpace(scanner.Text()), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		values[parts[0]] = strings.Trim(parts[1], `"'`)
+	}
+
+	if values["PRETTY_NAME"] != "" {
+		return values["PRETTY_NAME"]
+	}
+	if values["NAME"] != "" {
+		return strings.TrimSpace(values["NAME"] + " " + values["VERSION_ID"])
+	}
+
+	return "linux"
+}
+
+func hardwareIDFrom(identity string) string {
+	hash := sha256.Sum256([]byte(identity))
+	return hex.EncodeToString(hash[:16])
+}
@@ -3,6 +3,8 @@ package api
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
@@ -13,8 +15,79 @@ func isNullOrEmpty(value string) bool {
 	return len(strings.TrimSpace(value)) <= 0
 }
 
-func doHTTPRequest(method string, headers map[string]string, url string, payload []byte, timeout time.Duration) (*http.Response, []byte, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+func removeString(values []string, value string) []string {
+	result := []string{}
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+// diffLines returns a line diff of `before` and `after`, unchanged lines are prefixed with
+// two spaces, removed ones with "- " and added ones with "+ ". Equal inputs return "".
+func diffLines(before string, after string) string {
+	if before == after {
+		return ""
+	}
+
+	a := strings.Split(strings.TrimSuffix(before, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(after, "\n"), "\n")
+	if before == "" {
+		a = []string{}
+	}
+	if after == "" {
+		b = []string{}
+	}
+
+	// longest common subsequence, the configs are small enough for the full table
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var builder strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			builder.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			builder.WriteString("+ " + b[j] + "\n")
+			j++
+		default:
+			builder.WriteString("- " + a[i] + "\n")
+			i++
+		}
+	}
+
+	return builder.String()
+}
+
+// doHTTPRequest uses `transport`, or http.DefaultTransport when nil, so tests can record
+// and replay every exchange of the SDK clients. The request is part of the operation in `ctx`,
+// its timeout starts once the rate limiter of `ctx` lets it go.
+func doHTTPRequest(ctx context.Context, transport http.RoundTripper, method string, headers map[string]string, url string, payload []byte, timeout time.Duration) (*http.Response, []byte, error) {
+	if err := waitRateLimit(ctx, url); err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	request, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(payload))
@@ -27,7 +100,8 @@ func doHTTPRequest(method string, headers map[string]string, url string, payload
 	}
 
 	client := &http.Client{
-		Timeout: timeout,
+		Transport: transport,
+		Timeout:   timeout,
 	}
 	response, err := client.Do(request)
 	if err != nil {
@@ -39,3 +113,60 @@ func doHTTPRequest(method string, headers map[string]string, url string, payload
 
 	return response, data, err
 }
+
+// doHTTPRequestToWriter has no deadline for the whole transfer, `timeout` bounds the wait for
+// the response headers and then every stall of the body. Like doHTTPRequest it first waits on
+// the rate limiter of `ctx`.
+func doHTTPRequestToWriter(ctx context.Context, transport http.RoundTripper, method string, headers map[string]string, url string, payload []byte, timeout time.Duration, writer io.Writer) (*http.Response, int64, error) {
+	if err := waitRateLimit(ctx, url); err != nil {
+		return nil, 0, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stalled := time.AfterFunc(timeout, cancel)
+	defer stalled.Stop()
+
+	request, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for key, val := range headers {
+		request.Header.Set(key, val)
+	}
+
+	client := &http.Client{
+		Transport: transport,
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return response, 0, fmt.Errorf("unexpected response status %s", response.Status)
+	}
+
+	written, err := io.Copy(writer, &idleTimeoutReader{reader: response.Body, timer: stalled, timeout: timeout})
+
+	return response, written, err
+}
+
+// idleTimeoutReader pushes `timer` back by `timeout` every time data comes in.
+type idleTimeoutReader struct {
+	reader  io.Reader
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (thisRef *idleTimeoutReader) Read(p []byte) (int, error) {
+	n, err := thisRef.reader.Read(p)
+	if n > 0 {
+		thisRef.timer.Reset(thisRef.timeout)
+	}
+
+	return n, err
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+// Tracer starts the spans of the SDK operations and of their HTTP calls, a span started with
+// the context of another one is its child. It maps onto an OpenTelemetry trace.Tracer.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// Metrics receives the METRIC_API_* counters and histograms, it maps onto OpenTelemetry
+// instruments or Prometheus vectors keyed by the label names.
+type Metrics interface {
+	AddCounter(name string, value float64, labels map[string]string)
+	ObserveHistogram(name string, value float64, labels map[string]string)
+}
+
+// Instrumentation is what the SDK reports to, a nil Tracer or Metrics reports nothing.
+type Instrumentation struct {
+	Tracer  Tracer
+	Metrics Metrics
+}
+
+func (thisRef Instrumentation) enabled() bool {
+	return thisRef.Tracer != nil || thisRef.Metrics != nil
+}
+
+func (thisRef Instrumentation) tracer() Tracer {
+	if thisRef.Tracer == nil {
+		return noopTracer{}
+	}
+
+	return thisRef.Tracer
+}
+
+func (thisRef Instrumentation) metrics() Metrics {
+	if thisRef.Metrics == nil {
+		return noopMetrics{}
+	}
+
+	return thisRef.Metrics
+}
+
+type noopTracer struct{}
+
+func (thisRef noopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (thisRef noopSpan) SetAttribute(key string, value interface{}) {}
+func (thisRef noopSpan) RecordError(err error)                      {}
+func (thisRef noopSpan) End()                                       {}
+
+type noopMetrics struct{}
+
+func (thisRef noopMetrics) AddCounter(name string, value float64, labels map[string]string)       {}
+func (thisRef noopMetrics) ObserveHistogram(name string, value float64, labels map[string]string) {}
+
+// instrumented is embedded by the clients reporting their operations, `ctx` holds the span
+// of the running operation so the nested operations and HTTP calls become its children. It
+// starts as the context of the SDK, with its rate limiter.
+type instrumented struct {
+	instrumentation Instrumentation
+	ctx             context.Context
+}
+
+func (thisRef instrumented) context() context.Context {
+	if thisRef.ctx == nil {
+		return context.Background()
+	}
+
+	return thisRef.ctx
+}
+
+// startOperation returns the operation and a copy of `thisRef` bound to its span.
+func (thisRef instrumented) startOperation(name string) (*operation, instrumented) {
+	ctx, span := thisRef.instrumentation.tracer().Start(thisRef.context(), name)
+
+	op := &operation{
+		name:    name,
+		metrics: thisRef.instrumentation.metrics(),
+		span:    span,
+		start:   time.Now(),
+	}
+	thisRef.ctx = ctx
+
+	return op, thisRef
+}
+
+// startClientOperation starts an operation of a service built on `apiClient`, the returned
+// client sends its requests as part of it. Other Client implementations are not instrumented.
+func startClientOperation(apiClient Client, name string) (*operation, Client) {
+	restClient, ok := apiClient.(*client)
+	if !ok {
+		op, _ := instrumented{}.startOperation(name)
+		return op, apiClient
+	}
+
+	bound := *restClient
+	op, boundInstrumented := restClient.instrumented.startOperation(name)
+	bound.instrumented = boundInstrumented
+
+	return op, &bound
+}
+
+type operation struct {
+	name    string
+	metrics Metrics
+	span    Span
+	start   time.Time
+}
+
+// end reports the outcome, pass it the address of the named error result.
+func (thisRef *operation) end(errx *errorx.Error) {
+	if errx != nil && *errx != nil {
+		thisRef.span.RecordError(*errx)
+		thisRef.span.SetAttribute("error.code", (*errx).Code())
+		thisRef.metrics.AddCounter(apiContracts.METRIC_API_ERRORS, 1, map[string]string{
+			"operation": thisRef.name,
+			"code":      strconv.Itoa((*errx).Code()),
+		})
+	}
+
+	thisRef.metrics.ObserveHistogram(apiContracts.METRIC_API_OPERATION_DURATION, time.Since(thisRef.start).Seconds(), map[string]string{"operation": thisRef.name})
+	thisRef.span.End()
+}
+
+// retry counts one more attempt of the operation.
+func (thisRef *operation) retry() {
+	thisRef.span.SetAttribute("retry", true)
+	thisRef.metrics.AddCounter(apiContracts.METRIC_API_RETRIES, 1, map[string]string{"operation": thisRef.name})
+}
+
+// NewInstrumentedTransport reports a span and the request duration of every request handed to
+// `next`, http.DefaultTransport when nil. The span is a child of the one in the request context.
+func NewInstrumentedTransport(instrumentation Instrumentation, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &instrumentedTransport{
+		instrumentation: instrumentation,
+		next:            next,
+	}
+}
+
+type instrumentedTransport struct {
+	instrumentation Instrumentation
+	next            http.RoundTripper
+}
+
+func (thisRef *instrumentedTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	group := RateLimitGroupOf(request.URL.Path)
+
+	ctx, span := thisRef.instrumentation.tracer().Start(request.Context(), "HTTP "+request.Method)
+	span.SetAttribute("http.method", request.Method)
+	// the endpoint group, the paths carry IDs and some carry secrets
+	span.SetAttribute("http.route", string(group))
+	defer span.End()
+
+	start := time.Now()
+	response, err := thisRef.next.RoundTrip(request.WithContext(ctx))
+
+	status := "error"
+	if err != nil {
+		span.RecordError(err)
+	} else {
+		status = strconv.Itoa(response.StatusCode)
+		span.SetAttribute("http.status_code", response.StatusCode)
+	}
+
+	thisRef.instrumentation.metrics().ObserveHistogram(apiContracts.METRIC_API_REQUEST_DURATION, time.Since(start).Seconds(), map[string]string{
+		"group":  string(group),
+		"method": request.Method,
+		"status": status,
+	})
+
+	return response, err
+}
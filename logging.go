@@ -0,0 +1,148 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+// Logger takes a message and key/value pairs like log/slog, a *slog.Logger fits as is.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+const maxLoggedBodySize = 1 << 20
+
+// The JSON fields never written to a log, the headers are not logged at all.
+var logRedactedFields = []string{
+	"password", "authhash", "service_authhash", "token", "apikey", "secret", "key",
+	"registration_key", "bulkidentificationcode", "registration", "devicesecret", "projectsecret",
+}
+
+// NewLoggingTransport logs every request handed to `next`, http.DefaultTransport when nil:
+//
+//   - Info "api request" with method, endpoint, status and duration
+//   - Warn instead, with error, when the API refuses the request
+//   - Error instead, with error, when there is no response
+//   - Debug "api request body" and "api response body" with the redacted JSON bodies
+func NewLoggingTransport(logger Logger, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &loggingTransport{
+		logger: logger,
+		next:   next,
+	}
+}
+
+type loggingTransport struct {
+	logger Logger
+	next   http.RoundTripper
+}
+
+func (thisRef *loggingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	method := request.Method
+	endpoint := apiContracts.RedactPath(request.URL.Path)
+
+	if request.Body != nil {
+		body, err := ioutil.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		if len(body) > 0 {
+			thisRef.logger.Debug("api request body", "method", method, "endpoint", endpoint, "body", apiContracts.RedactJSON(body, logRedactedFields))
+		}
+	}
+
+	start := time.Now()
+	response, err := thisRef.next.RoundTrip(request)
+	duration := time.Since(start)
+
+	if err != nil {
+		thisRef.logger.Error("api request", "method", method, "endpoint", endpoint, "duration", duration, "error", err.Error())
+		return nil, err
+	}
+
+	apiError := ""
+	if isLoggedBody(response) {
+		// a reply of unknown length is only read up to the limit, a longer one streams on
+		body, err := ioutil.ReadAll(io.LimitReader(response.Body, maxLoggedBodySize+1))
+		if err != nil {
+			response.Body.Close()
+			thisRef.logger.Error("api request", "method", method, "endpoint", endpoint, "status", response.StatusCode, "duration", duration, "error", err.Error())
+			return nil, err
+		}
+
+		if len(body) > maxLoggedBodySize {
+			response.Body = readCloser{io.MultiReader(bytes.NewReader(body), response.Body), response.Body}
+		} else {
+			response.Body.Close()
+			response.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+			thisRef.logger.Debug("api response body", "method", method, "endpoint", endpoint, "status", response.StatusCode, "body", apiContracts.RedactJSON(body, logRedactedFields))
+			apiError = apiErrorOf(body)
+		}
+	}
+
+	if response.StatusCode >= http.StatusBadRequest && apiError == "" {
+		apiError = response.Status
+	}
+
+	if apiError != "" {
+		thisRef.logger.Warn("api request", "method", method, "endpoint", endpoint, "status", response.StatusCode, "duration", duration, "error", apiError)
+	} else {
+		thisRef.logger.Info("api request", "method", method, "endpoint", endpoint, "status", response.StatusCode, "duration", duration)
+	}
+
+	return response, nil
+}
+
+// isLoggedBody tells if the reply may be small text that can be read here, downloads stream
+// through untouched.
+func isLoggedBody(response *http.Response) bool {
+	if response.ContentLength > maxLoggedBodySize {
+		return false
+	}
+
+	contentType := response.Header.Get("Content-Type")
+	return contentType == "" || strings.Contains(contentType, "json") || strings.HasPrefix(contentType, "text/")
+}
+
+// readCloser reads the buffered start of a body and then the rest, and closes the original.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// apiErrorOf returns the reason of a `"status": "false"` REST reply.
+func apiErrorOf(body []byte) string {
+	var response struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	}
+	if json.Unmarshal(body, &response) != nil {
+		return ""
+	}
+
+	if response.Status == apiContracts.API_ERROR_CODE_STATUS_FALSE {
+		if response.Reason == "" {
+			return "status false"
+		}
+		return response.Reason
+	}
+
+	return ""
+}
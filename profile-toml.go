@@ -0,0 +1,289 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseTOML reads the subset of TOML a profile config needs: comments, `[a.b."c d"]` tables
+// and `key = value` pairs of strings, integers, floats and booleans.
+func parseTOML(data []byte) (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	table := root
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(stripTOMLComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: invalid table %s", lineNumber, line)
+			}
+
+			keys, err := parseTOMLKeys(line[1 : len(line)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", lineNumber, err)
+			}
+
+			if table, err = tomlTable(root, keys); err != nil {
+				return nil, fmt.Errorf("line %d: %s", lineNumber, err)
+			}
+			continue
+		}
+
+		equal := indexOutsideTOMLString(line, '=')
+		if equal < 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNumber)
+		}
+
+		keys, err := parseTOMLKeys(line[:equal])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNumber, err)
+		}
+
+		value, err := parseTOMLValue(strings.TrimSpace(line[equal+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNumber, err)
+		}
+
+		parent, err := tomlTable(table, keys[:len(keys)-1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNumber, err)
+		}
+
+		key := keys[len(keys)-1]
+		if _, ok := parent[key]; ok {
+			return nil, fmt.Errorf("line %d: duplicate key %s", lineNumber, key)
+		}
+		parent[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// tomlTable walks down `keys` from `table`, creating the missing tables.
+func tomlTable(table map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	for _, key := range keys {
+		child, ok := table[key]
+		if !ok {
+			child = map[string]interface{}{}
+			table[key] = child
+		}
+
+		childTable, ok := child.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s is not a table", key)
+		}
+		table = childTable
+	}
+
+	return table, nil
+}
+
+func parseTOMLKeys(text string) ([]string, error) {
+	keys := []string{}
+	for text = strings.TrimSpace(text); ; {
+		var key string
+		if strings.HasPrefix(text, `"`) {
+			end := tomlBasicStringEnd(text)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated key %s", text)
+			}
+
+			var err error
+			if key, err = unescapeTOML(text[1:end]); err != nil {
+				return nil, fmt.Errorf("invalid key %s: %s", text[:end+1], err)
+			}
+			text = strings.TrimSpace(text[end+1:])
+		} else if strings.HasPrefix(text, "'") {
+			end := strings.IndexByte(text[1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated key %s", text)
+			}
+
+			key = text[1 : end+1]
+			text = strings.TrimSpace(text[end+2:])
+		} else {
+			end := strings.IndexByte(text, '.')
+			if end < 0 {
+				end = len(text)
+			}
+
+			key = strings.TrimSpace(text[:end])
+			text = text[end:]
+			if !isTOMLBareKey(key) {
+				return nil, fmt.Errorf("invalid key %q", key)
+			}
+		}
+		keys = append(keys, key)
+
+		if text == "" {
+			return keys, nil
+		}
+		if !strings.HasPrefix(text, ".") {
+			return nil, fmt.Errorf("invalid key %q", text)
+		}
+		text = strings.TrimSpace(text[1:])
+	}
+}
+
+func isTOMLBareKey(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseTOMLValue(text string) (interface{}, error) {
+	switch {
+	case text == "":
+		return nil, fmt.Errorf("missing value")
+	case text == "true":
+		return true, nil
+	case text == "false":
+		return false, nil
+	case strings.HasPrefix(text, `"`):
+		if tomlBasicStringEnd(text) != len(text)-1 {
+			return nil, fmt.Errorf("invalid string %s", text)
+		}
+		value, err := unescapeTOML(text[1 : len(text)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s: %s", text, err)
+		}
+		return value, nil
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") || strings.Contains(text[1:len(text)-1], "'") {
+			return nil, fmt.Errorf("invalid string %s", text)
+		}
+		return text[1 : len(text)-1], nil
+	}
+
+	number := strings.Replace(text, "_", "", -1)
+	if value, err := strconv.ParseInt(number, 10, 64); err == nil {
+		return value, nil
+	}
+	if value, err := strconv.ParseFloat(number, 64); err == nil {
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("unsupported value %s", text)
+}
+
+// stripTOMLComment cuts the line at the first # that is not inside a string.
+func stripTOMLComment(line string) string {
+	if i := indexOutsideTOMLString(line, '#'); i >= 0 {
+		return line[:i]
+	}
+
+	return line
+}
+
+// indexOutsideTOMLString is the index of the first `target` that is not inside a string, or -1.
+func indexOutsideTOMLString(line string, target byte) int {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote == 0 && c == target:
+			return i
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == '"' && c == '\\':
+			i++
+		case c == quote:
+			quote = 0
+		}
+	}
+
+	return -1
+}
+
+// tomlBasicStringEnd is the index of the quote closing the basic string `text` starts with,
+// or -1 when it is not closed.
+func tomlBasicStringEnd(text string) int {
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+
+	return -1
+}
+
+// unescapeTOML decodes the escapes of a basic string, TOML has fewer than Go and no \x.
+func unescapeTOML(text string) (string, error) {
+	var value strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c != '\\' {
+			if c < 0x20 && c != '\t' || c == 0x7f {
+				return "", fmt.Errorf("control character %q", c)
+			}
+			value.WriteByte(c)
+			continue
+		}
+
+		i++
+		if i == len(text) {
+			return "", fmt.Errorf("unterminated escape")
+		}
+
+		switch text[i] {
+		case 'b':
+			value.WriteByte('\b')
+		case 't':
+			value.WriteByte('\t')
+		case 'n':
+			value.WriteByte('\n')
+		case 'f':
+			value.WriteByte('\f')
+		case 'r':
+			value.WriteByte('\r')
+		case 'e':
+			value.WriteByte(0x1b)
+		case '"':
+			value.WriteByte('"')
+		case '\\':
+			value.WriteByte('\\')
+		case 'u', 'U':
+			digits := 4
+			if text[i] == 'U' {
+				digits = 8
+			}
+			if i+digits >= len(text) {
+				return "", fmt.Errorf("short unicode escape \\%s", text[i:])
+			}
+
+			code, err := strconv.ParseUint(text[i+1:i+1+digits], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(code)) {
+				return "", fmt.Errorf("invalid unicode escape \\%s", text[i:i+1+digits])
+			}
+			value.WriteRune(rune(code))
+			i += digits
+		default:
+			return "", fmt.Errorf("invalid escape \\%c", text[i])
+		}
+	}
+
+	return value.String(), nil
+}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+// Clients are all the SDK clients of one profile.
+type Clients struct {
+	Profile        apiContracts.Profile
+	Authentication apiContracts.Authentication // empty when the profile has no credentials
+
+	Client           Client
+	GraphQL          GraphQLClient
+	Restore          RestoreClient
+	Certificate      CertificateClient
+	Device           Device
+	Service          Service
+	Proxy            Proxy
+	AutoRegistration AutoRegistration
+}
+
+// LoadClients is LoadProfile followed by NewClientsFromProfile.
+func LoadClients(path string, name string) (Clients, errorx.Error) {
+	profile, errx := LoadProfile(path, name)
+	if errx != nil {
+		return Clients{}, errx
+	}
+
+	return NewClientsFromProfile(profile)
+}
+
+// NewClientsFromProfile builds every client of `profile` on one SDK, see NewSDK.
+func NewClientsFromProfile(profile apiContracts.Profile) (Clients, errorx.Error) {
+	sdk, errx := NewSDK(profile)
+	if errx != nil {
+		return Clients{}, errx
+	}
+
+	clients := Clients{
+		Profile:          sdk.Profile(),
+		Client:           sdk.Client(),
+		GraphQL:          sdk.GraphQL(),
+		Restore:          sdk.Restore(),
+		Certificate:      sdk.Certificates(),
+		Device:           sdk.Devices(),
+		Service:          sdk.Services(),
+		Proxy:            sdk.Proxies(),
+		AutoRegistration: sdk.AutoRegistration(),
+	}
+	if session := sdk.Session(); session != nil {
+		clients.Authentication = session.Authentication()
+	}
+
+	return clients, nil
+}
+
+// LoadProfile returns the profile `name` of the config file at `path`.
+// An empty `name` means ENV_PROFILE, then the default profile of the file. An empty `path`
+// means ENV_CONFIG, and without any file the profile only comes from the environment.
+// The ENV_* variables override the file and the DEFAULT_* values fill in the rest.
+func LoadProfile(path string, name string) (apiContracts.Profile, errorx.Error) {
+	if path == "" {
+		path = os.Getenv(apiContracts.ENV_CONFIG)
+	}
+	if name == "" {
+		name = os.Getenv(apiContracts.ENV_PROFILE)
+	}
+
+	profile := apiContracts.Profile{Name: name}
+	if path != "" {
+		config, errx := LoadProfileConfig(path)
+		if errx != nil {
+			return apiContracts.Profile{}, errx
+		}
+
+		if name == "" {
+			name = config.DefaultProfile
+		}
+
+		var ok bool
+		profile, ok = config.Profiles[name]
+		if !ok {
+			return apiContracts.Profile{}, apiContracts.NewReasonError(apiContracts.ErrConfig_ProfileNotFound.Code(), fmt.Sprintf("no profile %q in %s", name, path))
+		}
+	}
+
+	profile, errx := applyProfileEnvironment(profile)
+	if errx != nil {
+		return apiContracts.Profile{}, errx
+	}
+
+	return profile.WithDefaults(), nil
+}
+
+// LoadProfileConfig reads a config file of named profiles, TOML for a `.toml` file and JSON
+// otherwise. Both use the same keys:
+//
+//	defaultProfile = "production"
+//
+//	[profiles.production]
+//	apiKey = "..."
+//	timeout = "30s"
+//
+//	[profiles.production.rateLimits.device]
+//	requestsPerSecond = 5
+//	burst = 10
+func LoadProfileConfig(path string) (apiContracts.ProfileConfig, errorx.Error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return apiContracts.ProfileConfig{}, apiContracts.NewErrorFromErr(apiContracts.ErrConfig_CantRead.Code(), err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		values, err := parseTOML(data)
+		if err != nil {
+			return apiContracts.ProfileConfig{}, apiContracts.NewErrorFromErr(apiContracts.ErrConfig_CantParse.Code(), err)
+		}
+
+		// the TOML tables decode exactly like the JSON objects
+		if data, err = json.Marshal(values); err != nil {
+			return apiContracts.ProfileConfig{}, apiContracts.NewErrorFromErr(apiContracts.ErrConfig_CantParse.Code(), err)
+		}
+	}
+
+	return parseProfileConfig(data)
+}
+
+type profileConfigFile struct {
+	DefaultProfile string                 `json:"defaultProfile"`
+	Profiles       map[string]profileFile `json:"profiles"`
+}
+
+type profileFile struct {
+	APIURL         string      `json:"apiURL"`
+	GraphQLURL     string      `json:"graphQLURL"`
+	RestoreURL     string      `json:"restoreURL"`
+	CertificateURL string      `json:"certificateURL"`
+	APIKey         string      `json:"apiKey"`
+	Username       string      `json:"username"`
+	Password       string      `json:"password"`
+	AuthHash       string      `json:"authHash"`
+	Timeout        interface{} `json:"timeout"` // "30s" or a number of seconds
+	UserAgent      string      `json:"userAgent"`
+
+	RateLimits apiContracts.RateLimits `json:"rateLimits"`
+}
+
+func parseProfileConfig(data []byte) (apiContracts.ProfileConfig, errorx.Error) {
+	var file profileConfigFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return apiContracts.ProfileConfig{}, apiContracts.NewErrorFromErr(apiContracts.ErrConfig_CantParse.Code(), err)
+	}
+
+	config := apiContracts.ProfileConfig{
+		DefaultProfile: file.DefaultProfile,
+		Profiles:       map[string]apiContracts.Profile{},
+	}
+	if config.DefaultProfile == "" {
+		config.DefaultProfile = apiContracts.DEFAULT_PROFILE_NAME
+	}
+
+	for name, profile := range file.Profiles {
+		timeout, err := parseProfileTimeout(profile.Timeout)
+		if err != nil {
+			return apiContracts.ProfileConfig{}, apiContracts.NewReasonError(apiContracts.ErrConfig_InvalidProfile.Code(), fmt.Sprintf("profile %q: %s", name, err))
+		}
+
+		for group := range profile.RateLimits {
+			if !isRateLimitGroup(group) {
+				return apiContracts.ProfileConfig{}, apiContracts.NewReasonError(apiContracts.ErrConfig_InvalidProfile.Code(), fmt.Sprintf("profile %q: unknown rate limit group %q", name, group))
+			}
+		}
+
+		config.Profiles[name] = apiContracts.Profile{
+			Name:           name,
+			APIURL:         profile.APIURL,
+			GraphQLURL:     profile.GraphQLURL,
+			RestoreURL:     profile.RestoreURL,
+			CertificateURL: profile.CertificateURL,
+			APIKey:         profile.APIKey,
+			Username:       profile.Username,
+			Password:       profile.Password,
+			AuthHash:       profile.AuthHash,
+			Timeout:        timeout,
+			UserAgent:      profile.UserAgent,
+			RateLimits:     profile.RateLimits,
+		}
+	}
+
+	return config, nil
+}
+
+func parseProfileTimeout(value interface{}) (time.Duration, error) {
+	switch value := value.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return time.Duration(value * float64(time.Second)), nil
+	case string:
+		return time.ParseDuration(value)
+	}
+
+	return 0, fmt.Errorf("invalid timeout %v", value)
+}
+
+func isRateLimitGroup(group apiContracts.RateLimitGroup) bool {
+	for _, known := range apiContracts.RateLimitGroups {
+		if group == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+func applyProfileEnvironment(profile apiContracts.Profile) (apiContracts.Profile, errorx.Error) {
+	overrides := map[string]*string{
+		apiContracts.ENV_API_URL:         &profile.APIURL,
+		apiContracts.ENV_GRAPHQL_URL:     &profile.GraphQLURL,
+		apiContracts.ENV_RESTORE_URL:     &profile.RestoreURL,
+		apiContracts.ENV_CERTIFICATE_URL: &profile.CertificateURL,
+		apiContracts.ENV_APIKEY:          &profile.APIKey,
+		apiContracts.ENV_USERNAME:        &profile.Username,
+		apiContracts.ENV_PASSWORD:        &profile.Password,
+		apiContracts.ENV_AUTHHASH:        &profile.AuthHash,
+	}
+
+	for name, field := range overrides {
+		if value := os.Getenv(name); value != "" {
+			*field = value
+		}
+	}
+
+	if value := os.Getenv(apiContracts.ENV_API_TIMEOUT); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return apiContracts.Profile{}, apiContracts.NewReasonError(apiContracts.ErrConfig_InvalidProfile.Code(), fmt.Sprintf("%s: %s", apiContracts.ENV_API_TIMEOUT, err))
+		}
+
+		profile.Timeout = timeout
+	}
+
+	return profile, nil
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+// CheckHealth probes the endpoint of an already created connection. A nil error with
+// `Reachable == false` means the probe ran but the remote service did not answer.
+func (thisRef proxy) CheckHealth(connection apiContracts.ProxyConnectionInfo, request apiContracts.ProxyHealthCheckRequest) (apiContracts.ProxyHealthCheckResponse, errorx.Error) {
+	endpoint := proxyEndpoint(connection)
+	if endpoint == "" {
+		return apiContracts.ProxyHealthCheckResponse{}, apiContracts.ErrAPI_ProxyHealth_NoEndpoint
+	}
+
+	timeout := request.Timeout
+	if timeout <= 0 {
+		timeout = apiContracts.DEFAULT_PROXY_HEALTH_CHECK_TIMEOUT
+	}
+
+	response := apiContracts.ProxyHealthCheckResponse{
+		Endpoint: endpoint,
+	}
+
+	start := time.Now()
+
+	var err error
+	switch request.Mode {
+	case apiContracts.ProxyHealthCheckTCP:
+		err = probeTCP(endpoint, timeout)
+	case apiContracts.ProxyHealthCheckTLS:
+		err = probeTLS(endpoint, timeout, request)
+	case apiContracts.ProxyHealthCheckHTTP:
+		response.HTTPStatusCode, err = probeHTTP(connection, endpoint, timeout, request)
+	default:
+		return apiContracts.ProxyHealthCheckResponse{}, apiContracts.ErrAPI_ProxyHealth_BadMode
+	}
+
+	response.Duration = time.Since(start)
+	if err != nil {
+		response.Reason = err.Error()
+		return response, nil
+	}
+
+	response.Reachable = true
+	return response, nil
+}
+
+// CreateAndCheckHealth creates the connection and probes it right away. The connection
+// is left in place even if the probe fails, so the caller decides whether to delete it.
+func (thisRef proxy) CreateAndCheckHealth(request apiContracts.CreateProxyRequest, healthRequest apiContracts.ProxyHealthCheckRequest) (apiContracts.CreateProxyResponse, apiContracts.ProxyHealthCheckResponse, errorx.Error) {
+	response, errx := thisRef.Create(request)
+	if errx != nil {
+		return apiContracts.CreateProxyResponse{}, apiContracts.ProxyHealthCheckResponse{}, errx
+	}
+
+	health, errx := thisRef.CheckHealth(response.Connection, healthRequest)
+	if errx != nil {
+		return response, apiContracts.ProxyHealthCheckResponse{}, errx
+	}
+
+	return response, health, nil
+}
+
+func proxyEndpoint(connection apiContracts.ProxyConnectionInfo) string {
+	if connection.ProxyServer != "" {
+		port := connection.ProxyPort
+		if port == "" && connection.ProxyServerPort > 0 {
+			port = strconv.Itoa(connection.ProxyServerPort)
+		}
+		if port != "" {
+			return net.JoinHostPort(connection.ProxyServer, port)
+		}
+	}
+
+	if connection.ProxyURL != "" && !strings.Contains(connection.ProxyURL, "://") {
+		return connection.ProxyURL
+	}
+
+	if connection.Proxy != "" {
+		parsedURL, err := url.Parse(connection.Proxy)
+		if err == nil && parsedURL.Host != "" {
+			if parsedURL.Port() != "" {
+				return parsedURL.Host
+			}
+			if parsedURL.Scheme == "https" {
+				return net.JoinHostPort(parsedURL.Hostname(), "443")
+			}
+			return net.JoinHostPort(parsedURL.Hostname(), "80")
+		}
+	}
+
+	return ""
+}
+
+func probeTCP(endpoint string, timeout time.Duration) error {
+	conn, err := net.DialTimeout("tcp", endpoint, timeout)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+func probeTLS(endpoint string, timeout time.Duration, request apiContracts.ProxyHealthCheckRequest) error {
+	serverName := request.TLSServerName
+	if serverName == "" {
+		host, _, err := net.SplitHostPort(endpoint)
+		if err != nil {
+			return err
+		}
+		serverName = host
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", endpoint, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: request.TLSSkipVerify,
+	})
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+func probeHTTP(connection apiContracts.ProxyConnectionInfo, endpoint string, timeout time.Duration, request apiContracts.ProxyHealthCheckRequest) (int, error) {
+	path := request.HTTPPath
+	if path == "" {
+		path = apiContracts.DEFAULT_PROXY_HEALTH_CHECK_HTTP_PATH
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	scheme := "http"
+	if strings.HasPrefix(connection.Proxy, "https://") {
+		scheme = "https"
+	}
+	targetURL := fmt.Sprintf("%s://%s%s", scheme, endpoint, path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				ServerName:         request.TLSServerName,
+				InsecureSkipVerify: request.TLSSkipVerify,
+			},
+		},
+		// any answer from the service counts, do not chase redirects
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	httpResponse, err := client.Do(httpRequest)
+	if err != nil {
+		return 0, err
+	}
+	httpResponse.Body.Close()
+
+	if httpResponse.StatusCode >= 500 {
+		return httpResponse.StatusCode, fmt.Errorf("service answered with %s", httpResponse.Status)
+	}
+
+	return httpResponse.StatusCode, nil
+}
@@ -2,7 +2,6 @@ package api
 
 import (
 	"encoding/json"
-	"strings"
 
 	apiContracts "github.com/remoteit/sdk-go/contracts"
 	errorx "github.com/remoteit/systemkit-errorx"
@@ -11,6 +10,9 @@ import (
 type Proxy interface {
 	Create(request apiContracts.CreateProxyRequest) (apiContracts.CreateProxyResponse, errorx.Error)
 	Delete(request apiContracts.DeleteProxyRequest) (apiContracts.DeleteProxyResponse, errorx.Error)
+
+	CheckHealth(connection apiContracts.ProxyConnectionInfo, request apiContracts.ProxyHealthCheckRequest) (apiContracts.ProxyHealthCheckResponse, errorx.Error)
+	CreateAndCheckHealth(request apiContracts.CreateProxyRequest, healthRequest apiContracts.ProxyHealthCheckRequest) (apiContracts.CreateProxyResponse, apiContracts.ProxyHealthCheckResponse, errorx.Error)
 }
 
 func NewProxy(apiClient Client) Proxy {
@@ -23,7 +25,11 @@ type proxy struct {
 	apiClient Client
 }
 
-func (thisRef proxy) Create(request apiContracts.CreateProxyRequest) (apiContracts.CreateProxyResponse, errorx.Error) {
+func (thisRef proxy) Create(request apiContracts.CreateProxyRequest) (_ apiContracts.CreateProxyResponse, errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "Proxy.Create")
+	defer op.end(&errx)
+
 	body, err := json.Marshal(request)
 	if err != nil {
 		return apiContracts.CreateProxyResponse{}, apiContracts.ErrAPI_ProxyCreate_CantPrepRequest
@@ -40,19 +46,17 @@ func (thisRef proxy) Create(request apiContracts.CreateProxyRequest) (apiContrac
 	}
 
 	if response.Status != apiContracts.API_ERROR_CODE_STATUS_TRUE {
-		if strings.Contains(response.Reason, apiContracts.API_ERROR_CODE_REASON_SERVICE_NOT_FOUND_FOR_UID) {
-			return apiContracts.CreateProxyResponse{}, apiContracts.ErrAPI_ProxyCreate_NoServiceFound
-		}
-		if !(len(strings.TrimSpace(response.Reason)) <= 0) {
-			return apiContracts.CreateProxyResponse{}, errorx.New(apiContracts.ErrAPI_ProxyCreate_Generic, response.Reason)
-		}
-		return apiContracts.CreateProxyResponse{}, apiContracts.ErrAPI_ProxyCreate_Unknown
+		return apiContracts.CreateProxyResponse{}, apiContracts.ParseAPIError(response.Reason).ToError(proxyCreateAPIErrors, apiContracts.ErrAPI_ProxyCreate_Generic, apiContracts.ErrAPI_ProxyCreate_Unknown)
 	}
 
 	return response, nil
 }
 
-func (thisRef proxy) Delete(request apiContracts.DeleteProxyRequest) (apiContracts.DeleteProxyResponse, errorx.Error) {
+func (thisRef proxy) Delete(request apiContracts.DeleteProxyRequest) (_ apiContracts.DeleteProxyResponse, errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "Proxy.Delete")
+	defer op.end(&errx)
+
 	body, err := json.Marshal(request)
 	if err != nil {
 		return apiContracts.DeleteProxyResponse{}, apiContracts.ErrAPI_ProxyDelete_CantPrepRequest
@@ -69,7 +73,7 @@ func (thisRef proxy) Delete(request apiContracts.DeleteProxyRequest) (apiContrac
 	}
 
 	if response.Status != apiContracts.API_ERROR_CODE_STATUS_TRUE {
-		return apiContracts.DeleteProxyResponse{}, apiContracts.ErrAPI_ProxyDelete_Unknown
+		return apiContracts.DeleteProxyResponse{}, apiContracts.ParseAPIError(response.Reason).ToError(proxyDeleteAPIErrors, apiContracts.ErrAPI_ProxyDelete_Generic, apiContracts.ErrAPI_ProxyDelete_Unknown)
 	}
 
 	return response, nil
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+// RateLimiter holds one token bucket per endpoint group, it is safe for concurrent use.
+type RateLimiter interface {
+	// Wait blocks until a request of `group` may go out, or until `ctx` is done.
+	Wait(ctx context.Context, group apiContracts.RateLimitGroup) error
+}
+
+func NewRateLimiter(limits apiContracts.RateLimits) RateLimiter {
+	buckets := map[apiContracts.RateLimitGroup]*tokenBucket{}
+	for group, limit := range limits {
+		if limit.RequestsPerSecond <= 0 {
+			continue
+		}
+
+		burst := limit.Burst
+		if burst < 1 {
+			burst = 1
+		}
+
+		buckets[group] = &tokenBucket{
+			rate:   limit.RequestsPerSecond,
+			burst:  float64(burst),
+			tokens: float64(burst),
+		}
+	}
+
+	return &rateLimiter{buckets: buckets}
+}
+
+type rateLimiter struct {
+	buckets map[apiContracts.RateLimitGroup]*tokenBucket
+}
+
+func (thisRef *rateLimiter) Wait(ctx context.Context, group apiContracts.RateLimitGroup) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	bucket, ok := thisRef.buckets[group]
+	if !ok {
+		return nil
+	}
+
+	delay := bucket.take(time.Now())
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		bucket.giveBack()
+		return ctx.Err()
+	}
+}
+
+type tokenBucket struct {
+	mutex  sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64 // negative while requests wait for their token
+	last   time.Time
+}
+
+// take reserves a token and returns how long to wait before it can be used.
+func (thisRef *tokenBucket) take(now time.Time) time.Duration {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	if !thisRef.last.IsZero() {
+		thisRef.tokens += now.Sub(thisRef.last).Seconds() * thisRef.rate
+		if thisRef.tokens > thisRef.burst {
+			thisRef.tokens = thisRef.burst
+		}
+	}
+	thisRef.last = now
+
+	thisRef.tokens--
+	if thisRef.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-thisRef.tokens / thisRef.rate * float64(time.Second))
+}
+
+// giveBack returns the token of a request that stopped waiting.
+func (thisRef *tokenBucket) giveBack() {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	thisRef.tokens++
+}
+
+// NewRateLimitedTransport waits on `limiter` before handing every request to `next`,
+// http.DefaultTransport when nil. The wait ends with the request context, so it counts against
+// the timeout of the client. Share one transport between the clients to share the limits, the
+// clients of NewSDK wait before their timeout starts instead.
+func NewRateLimitedTransport(limiter RateLimiter, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &rateLimitedTransport{
+		limiter: limiter,
+		next:    next,
+	}
+}
+
+type rateLimitedTransport struct {
+	limiter RateLimiter
+	next    http.RoundTripper
+}
+
+func (thisRef *rateLimitedTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if err := thisRef.limiter.Wait(request.Context(), RateLimitGroupOf(request.URL.Path)); err != nil {
+		if request.Body != nil {
+			request.Body.Close()
+		}
+		return nil, err
+	}
+
+	return thisRef.next.RoundTrip(request)
+}
+
+type rateLimiterKey struct{}
+
+// withRateLimiter returns `ctx` carrying `limiter`, the requests sent with it wait on it before
+// their timeout starts.
+func withRateLimiter(ctx context.Context, limiter RateLimiter) context.Context {
+	if limiter == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, rateLimiterKey{}, limiter)
+}
+
+// waitRateLimit waits on the limiter of `ctx`, if it carries one, for the group of `endpointURL`.
+func waitRateLimit(ctx context.Context, endpointURL string) error {
+	limiter, ok := ctx.Value(rateLimiterKey{}).(RateLimiter)
+	if !ok {
+		return nil
+	}
+
+	path := endpointURL
+	if parsed, err := url.Parse(endpointURL); err == nil {
+		path = parsed.Path
+	}
+
+	return limiter.Wait(ctx, RateLimitGroupOf(path))
+}
+
+// RateLimitGroupOf returns the group of the endpoint at `path`.
+func RateLimitGroupOf(path string) apiContracts.RateLimitGroup {
+	switch {
+	case strings.Contains(path, "/user/login"):
+		return apiContracts.RateLimitGroupAuth
+	case strings.Contains(path, "/device/connect"):
+		return apiContracts.RateLimitGroupConnect
+	case strings.Contains(path, "/graphql/"):
+		return apiContracts.RateLimitGroupGraphQL
+	case strings.Contains(path, "/device/"), strings.Contains(path, "/devices/"), strings.Contains(path, "/bulk/"):
+		return apiContracts.RateLimitGroupDevice
+	}
+
+	return apiContracts.RateLimitGroupOther
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+// AgentConfigWriter knows where the agent keeps its config and in which format.
+type AgentConfigWriter interface {
+	Render(config apiContracts.RestoreConfig) ([]byte, errorx.Error)
+	Verify(data []byte) errorx.Error
+
+	Read() (data []byte, exists bool, errx errorx.Error)
+	Write(data []byte) errorx.Error
+	Remove() errorx.Error
+	Backup(data []byte) (location string, errx errorx.Error)
+}
+
+// RestoreApplier writes a restored config over the agent config, backing up the current
+// one first and rolling back if the new one can't be written or doesn't verify.
+type RestoreApplier interface {
+	Apply(deviceID string, machineID string, dryRun bool) (apiContracts.RestoreApplyResult, errorx.Error)
+	ApplyConfig(config apiContracts.RestoreConfig, dryRun bool) (apiContracts.RestoreApplyResult, errorx.Error)
+}
+
+func NewRestoreApplier(restoreClient RestoreClient, writer AgentConfigWriter) RestoreApplier {
+	return &restoreApplier{
+		restoreClient: restoreClient,
+		writer:        writer,
+	}
+}
+
+type restoreApplier struct {
+	restoreClient RestoreClient
+	writer        AgentConfigWriter
+}
+
+func (thisRef restoreApplier) Apply(deviceID string, machineID string, dryRun bool) (apiContracts.RestoreApplyResult, errorx.Error) {
+	config, errx := thisRef.restoreClient.Restore(deviceID, machineID)
+	if errx != nil {
+		return apiContracts.RestoreApplyResult{}, errx
+	}
+
+	return thisRef.ApplyConfig(config, dryRun)
+}
+
+func (thisRef restoreApplier) ApplyConfig(config apiContracts.RestoreConfig, dryRun bool) (apiContracts.RestoreApplyResult, errorx.Error) {
+	// 1. render and compare
+	data, errx := thisRef.writer.Render(config)
+	if errx != nil {
+		return apiContracts.RestoreApplyResult{}, errx
+	}
+
+	current, exists, errx := thisRef.writer.Read()
+	if errx != nil {
+		return apiContracts.RestoreApplyResult{}, errx
+	}
+
+	result := apiContracts.RestoreApplyResult{
+		Config:  config,
+		DryRun:  dryRun,
+		Changed: !exists || !bytes.Equal(current, data),
+		Diff:    diffLines(maskSecrets(current, config), maskSecrets(data, config)),
+	}
+
+	if dryRun || !result.Changed {
+		return result, nil
+	}
+
+	// 2. back up
+	if exists {
+		result.BackupLocation, errx = thisRef.writer.Backup(current)
+		if errx != nil {
+			return apiContracts.RestoreApplyResult{}, errx
+		}
+	}
+
+	// 3. write and verify, roll back on any failure
+	if errx := thisRef.writer.Write(data); errx != nil {
+		if rollbackErrx := thisRef.rollback(current, exists); rollbackErrx != nil {
+			return result, rollbackErrx
+		}
+		return result, errx
+	}
+
+	written, _, errx := thisRef.writer.Read()
+	if errx == nil {
+		errx = thisRef.writer.Verify(written)
+	}
+	if errx != nil {
+		if rollbackErrx := thisRef.rollback(current, exists); rollbackErrx != nil {
+			return result, rollbackErrx
+		}
+		return result, apiContracts.NewError(apiContracts.ErrAPI_RestoreClient_CantVerifyConfig.Code(), fmt.Sprintf("%s: %s", apiContracts.ErrAPI_RestoreClient_CantVerifyConfig.Message(), errx.Message()))
+	}
+
+	return result, nil
+}
+
+// maskSecrets replaces the device and service secrets of `config`, and of the config in `data`
+// when it parses, with Redacted and a number. The diff is meant to be shown, a dry run
+// especially, and still tells a changed secret by its number.
+func maskSecrets(data []byte, config apiContracts.RestoreConfig) string {
+	// partially parsed or not at all when `data` is in another format, the secrets of `config` still go
+	parsed, _ := apiContracts.ParseRestoreConfig(data)
+
+	numbers := map[string]int{}
+	secrets := []string{}
+	for _, restored := range []apiContracts.RestoreConfig{config, parsed} {
+		for _, secret := range append([]string{restored.DeviceSecret}, serviceSecrets(restored)...) {
+			if _, ok := numbers[secret]; secret != "" && !ok {
+				numbers[secret] = len(numbers) + 1
+				secrets = append(secrets, secret)
+			}
+		}
+	}
+
+	// the longer first, a secret containing another is replaced whole
+	sort.SliceStable(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+
+	replacements := []string{}
+	for _, secret := range secrets {
+		replacements = append(replacements, secret, fmt.Sprintf("%s-%d", apiContracts.Redacted, numbers[secret]))
+	}
+
+	return strings.NewReplacer(replacements...).Replace(string(data))
+}
+
+func serviceSecrets(config apiContracts.RestoreConfig) []string {
+	secrets := []string{}
+	for _, service := range config.Services {
+		secrets = append(secrets, service.Secret)
+	}
+
+	return secrets
+}
+
+func (thisRef restoreApplier) rollback(previous []byte, existed bool) errorx.Error {
+	var errx errorx.Error
+	if existed {
+		errx = thisRef.writer.Write(previous)
+	} else {
+		errx = thisRef.writer.Remove()
+	}
+
+	if errx != nil {
+		return apiContracts.NewErrorFromErr(apiContracts.ErrAPI_RestoreClient_CantRollback.Code(), errx)
+	}
+
+	return nil
+}
+
+// NewFileAgentConfigWriter keeps the agent config as indented JSON at `path`, in the same
+// format the restore endpoint returns it.
+func NewFileAgentConfigWriter(path string) AgentConfigWriter {
+	return &fileAgentConfigWriter{
+		path: path,
+	}
+}
+
+type fileAgentConfigWriter struct {
+	path string
+}
+
+func (thisRef fileAgentConfigWriter) Render(config apiContracts.RestoreConfig) ([]byte, errorx.Error) {
+	var buffer bytes.Buffer
+	if err := json.Indent(&buffer, config.Raw, "", "  "); err != nil {
+		return nil, apiContracts.NewErrorFromErr(apiContracts.ErrAPI_RestoreClient_InvalidConfig.Code(), err)
+	}
+	buffer.WriteString("\n")
+
+	return buffer.Bytes(), nil
+}
+
+func (thisRef fileAgentConfigWriter) Verify(data []byte) errorx.Error {
+	_, errx := apiContracts.ParseRestoreConfig(data)
+	return errx
+}
+
+func (thisRef fileAgentConfigWriter) Read() ([]byte, bool, errorx.Error) {
+	data, err := ioutil.ReadFile(thisRef.path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, apiContracts.NewErrorFromErr(apiContracts.ErrAPI_RestoreClient_CantWriteConfig.Code(), err)
+	}
+
+	return data, true, nil
+}
+
+func (thisRef fileAgentConfigWriter) Write(data []byte) errorx.Error {
+	// the config holds device and service secrets
+	if err := writeFileAtomic(thisRef.path, data, 0600); err != nil {
+		return apiContracts.NewErrorFromErr(apiContracts.ErrAPI_RestoreClient_CantWriteConfig.Code(), err)
+	}
+
+	return nil
+}
+
+func (thisRef fileAgentConfigWriter) Remove() errorx.Error {
+	if err := os.Remove(thisRef.path); err != nil && !os.IsNotExist(err) {
+		return apiContracts.NewErrorFromErr(apiContracts.ErrAPI_RestoreClient_CantWriteConfig.Code(), err)
+	}
+
+	return nil
+}
+
+func (thisRef fileAgentConfigWriter) Backup(data []byte) (string, errorx.Error) {
+	location := fmt.Sprintf("%s.%s.bak", thisRef.path, time.Now().UTC().Format("20060102T150405.000000000"))
+	if err := writeFileAtomic(location, data, 0600); err != nil {
+		return "", apiContracts.NewErrorFromErr(apiContracts.ErrAPI_RestoreClient_CantBackup.Code(), err)
+	}
+
+	return location, nil
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+// SDK is every client of one profile. With credentials they all share one Session and send
+// the token of its last login, a refused token logs the session in again and the request is
+// sent once more. Without credentials only the calls that need no token work.
+type SDK interface {
+	Profile() apiContracts.Profile
+	Session() Session // nil without credentials
+
+	Client() Client
+	Devices() Device
+	Services() Service
+	Proxies() Proxy
+	GraphQL() GraphQLClient
+	Restore() RestoreClient
+	Certificates() CertificateClient
+	AutoRegistration() AutoRegistration
+}
+
+// NewSDK logs in when `profile` has credentials and builds every client on that session.
+func NewSDK(profile apiContracts.Profile) (SDK, errorx.Error) {
+	return NewSDKWithTransport(profile, nil)
+}
+
+// NewSDKWithTransport sends every request through `transport`, http.DefaultTransport when nil.
+func NewSDKWithTransport(profile apiContracts.Profile, transport http.RoundTripper) (SDK, errorx.Error) {
+	return NewSDKWithInstrumentation(profile, transport, Instrumentation{})
+}
+
+// NewSDKWithInstrumentation reports a span and metrics for every operation of the clients and
+// every HTTP call under it.
+func NewSDKWithInstrumentation(profile apiContracts.Profile, transport http.RoundTripper, instrumentation Instrumentation) (SDK, errorx.Error) {
+	return NewSDKWithContext(context.Background(), profile, transport, instrumentation)
+}
+
+// NewSDKWithContext sends every request of the clients with `ctx`, cancel it to stop the ones
+// waiting on the rate limits of `profile` or still running. All the clients share the limits,
+// a request waits for its turn before its timeout starts.
+func NewSDKWithContext(ctx context.Context, profile apiContracts.Profile, transport http.RoundTripper, instrumentation Instrumentation) (SDK, errorx.Error) {
+	profile = profile.WithDefaults()
+	if instrumentation.enabled() {
+		transport = NewInstrumentedTransport(instrumentation, transport)
+	}
+	if len(profile.RateLimits) > 0 {
+		ctx = withRateLimiter(ctx, NewRateLimiter(profile.RateLimits))
+	}
+
+	thisRef := &sdk{profile: profile}
+	base := instrumented{instrumentation: instrumentation, ctx: ctx}
+
+	// no token for the login itself, not the one another client of the package cached either
+	var tokens TokenSource = staticToken("")
+	thisRef.client = newClient(profile.APIURL, profile.APIKey, profile.Timeout, profile.UserAgent, tokens, transport, base)
+
+	if profile.HasCredentials() {
+		session, errx := NewSession(thisRef.client, profile.Username, profile.Password, profile.AuthHash)
+		if errx != nil {
+			return nil, errx
+		}
+
+		thisRef.session = session
+		tokens = session
+		thisRef.client = newClient(profile.APIURL, profile.APIKey, profile.Timeout, profile.UserAgent, session, transport, base)
+	}
+
+	thisRef.devices = NewDevice(thisRef.client)
+	thisRef.services = NewService(thisRef.client)
+	thisRef.proxies = NewProxy(thisRef.client)
+	thisRef.graphQL = newGraphQLClient(profile.GraphQLURL, tokens, profile.Timeout, profile.UserAgent, transport, base)
+	thisRef.restore = newRestoreClient(profile.RestoreURL, tokens, profile.Timeout, profile.UserAgent, transport, base)
+	thisRef.certificates = newCertificateClient(profile.CertificateURL, tokens, profile.Timeout, profile.UserAgent, transport, base)
+	thisRef.autoRegistration = NewAutoRegistration(thisRef.client)
+
+	return thisRef, nil
+}
+
+type sdk struct {
+	profile apiContracts.Profile
+	session Session
+
+	client           Client
+	devices          Device
+	services         Service
+	proxies          Proxy
+	graphQL          GraphQLClient
+	restore          RestoreClient
+	certificates     CertificateClient
+	autoRegistration AutoRegistration
+}
+
+func (thisRef *sdk) Profile() apiContracts.Profile {
+	return thisRef.profile
+}
+
+func (thisRef *sdk) Session() Session {
+	return thisRef.session
+}
+
+func (thisRef *sdk) Client() Client {
+	return thisRef.client
+}
+
+func (thisRef *sdk) Devices() Device {
+	return thisRef.devices
+}
+
+func (thisRef *sdk) Services() Service {
+	return thisRef.services
+}
+
+func (thisRef *sdk) Proxies() Proxy {
+	return thisRef.proxies
+}
+
+func (thisRef *sdk) GraphQL() GraphQLClient {
+	return thisRef.graphQL
+}
+
+func (thisRef *sdk) Restore() RestoreClient {
+	return thisRef.restore
+}
+
+func (thisRef *sdk) Certificates() CertificateClient {
+	return thisRef.certificates
+}
+
+func (thisRef *sdk) AutoRegistration() AutoRegistration {
+	return thisRef.autoRegistration
+}
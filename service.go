@@ -28,7 +28,11 @@ type service struct {
 	apiClient Client
 }
 
-func (thisRef service) Create(uid string, serviceType string) errorx.Error {
+func (thisRef service) Create(uid string, serviceType string) (errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "Service.Create")
+	defer op.end(&errx)
+
 	// Construct the request data to send to the API
 	type requestBody struct {
 		UID         string `json:"deviceaddress"`
@@ -60,19 +64,17 @@ func (thisRef service) Create(uid string, serviceType string) errorx.Error {
 
 	// Handle a variety of possible error conditions
 	if resp.Status == apiContracts.API_ERROR_CODE_STATUS_FALSE {
-		if strings.Contains(resp.Reason, apiContracts.API_ERROR_CODE_REASON_MISSING_API_TOKEN) {
-			return apiContracts.ErrAPI_Helpers_NoToken
-		}
-		if !(len(strings.TrimSpace(resp.Reason)) <= 0) {
-			return errorx.New(apiContracts.ErrAPI_Helpers_Generic, resp.Reason)
-		}
-		return apiContracts.ErrAPI_Helpers_Unknown
+		return apiContracts.ParseAPIError(resp.Reason).ToError(serviceCreateAPIErrors, apiContracts.ErrAPI_Helpers_Generic, apiContracts.ErrAPI_Helpers_Unknown)
 	}
 
 	return nil
 }
 
-func (thisRef service) Remove(uid string) errorx.Error {
+func (thisRef service) Remove(uid string) (errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "Service.Remove")
+	defer op.end(&errx)
+
 	// Construct the request data to send to the API
 	type request struct {
 		UID string `json:"deviceaddress"`
@@ -100,19 +102,17 @@ func (thisRef service) Remove(uid string) errorx.Error {
 	}
 
 	if resp.Status != apiContracts.API_ERROR_CODE_STATUS_TRUE {
-		if strings.Contains(resp.Reason, apiContracts.API_ERROR_CODE_REASON_SERVICE_NOT_FOUND_FOR_UID) {
-			return apiContracts.ErrAPI_Service_NoServiceFound
-		}
-		if !(len(strings.TrimSpace(resp.Reason)) <= 0) {
-			return errorx.New(apiContracts.ErrAPI_Service_Generic, resp.Reason)
-		}
-		return apiContracts.ErrAPI_Service_Unknown
+		return apiContracts.ParseAPIError(resp.Reason).ToError(serviceRemoveAPIErrors, apiContracts.ErrAPI_Service_Generic, apiContracts.ErrAPI_Service_Unknown)
 	}
 
 	return nil
 }
 
-func (thisRef service) GenerateUID(projectKey string, projectSecret string) (string, errorx.Error) {
+func (thisRef service) GenerateUID(projectKey string, projectSecret string) (_ string, errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "Service.GenerateUID")
+	defer op.end(&errx)
+
 	// Send the API request
 	raw, errx := thisRef.apiClient.Get(fmt.Sprintf("/device/address/%s/%s", projectKey, projectSecret))
 	if errx != nil {
@@ -135,10 +135,7 @@ func (thisRef service) GenerateUID(projectKey string, projectSecret string) (str
 	// Handle situations where the API returns an error and pass the reason
 	// back to the consumer.
 	if resp.Status == apiContracts.API_ERROR_CODE_STATUS_FALSE {
-		if !(len(strings.TrimSpace(resp.Reason)) <= 0) {
-			return "", errorx.New(apiContracts.ErrAPI_Helpers_Generic, resp.Reason)
-		}
-		return "", apiContracts.ErrAPI_Helpers_CantCreateServiceID
+		return "", apiContracts.ParseAPIError(resp.Reason).ToError(serviceGenerateUIDAPIErrors, apiContracts.ErrAPI_Helpers_Generic, apiContracts.ErrAPI_Helpers_CantCreateServiceID)
 	}
 
 	// For some reason, the API returned no UID in the request. This is unlikely
@@ -150,7 +147,11 @@ func (thisRef service) GenerateUID(projectKey string, projectSecret string) (str
 	return resp.UID, nil
 }
 
-func (thisRef service) Register(name string, uid string, hardwareID string, serviceType string, serviceTypeAsInt int) (string, errorx.Error) {
+func (thisRef service) Register(name string, uid string, hardwareID string, serviceType string, serviceTypeAsInt int) (_ string, errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "Service.Register")
+	defer op.end(&errx)
+
 	// Construct the request data to send to the API
 	type requestBody struct {
 		UID         string `json:"deviceaddress"`
@@ -211,32 +212,25 @@ func (thisRef service) Register(name string, uid string, hardwareID string, serv
 		return "", apiContracts.ErrAPI_Service_CantReadResponse
 	}
 
-	// fmt.Printf("RESPONSE\n%s\n", string(raw))
-
 	// Handle a variety of possible error conditions
 	if resp.Status == apiContracts.API_ERROR_CODE_STATUS_FALSE {
-		if strings.Contains(resp.Reason, apiContracts.API_ERROR_CODE_REASON_DEVICE_NOT_FOUND) {
-			return "", apiContracts.ErrAPI_Helpers_ServiceUIDNotFound
-		}
-		if strings.Contains(resp.Reason, apiContracts.API_ERROR_CODE_REASON_DUPLICATE_NAME) {
-			return "", errorx.New(apiContracts.ErrAPI_Helpers_Generic, fmt.Sprintf(`a device in your account already has the name "%s"`, name))
-		}
-		if strings.Contains(resp.Reason, apiContracts.API_ERROR_CODE_REASON_BAD_DEVICE_ADDRESS) {
-			return "", apiContracts.ErrAPI_Helpers_ServiceUIDMissing
+		apiError := apiContracts.ParseAPIError(resp.Reason)
+		if apiError.Code == apiContracts.API_ERROR_CODE_DUPLICATE_NAME {
+			errx := apiContracts.NewError(apiContracts.ErrAPI_Helpers_Generic, fmt.Sprintf(`a device in your account already has the name "%s"`, name)).WithReason(resp.Reason)
+			errx.APICode = apiError.Code
+			return "", errx
 		}
-		if strings.Contains(resp.Reason, apiContracts.API_ERROR_CODE_REASON_MISSING_API_TOKEN) {
-			return "", apiContracts.ErrAPI_Helpers_NoToken
-		}
-		if !(len(strings.TrimSpace(resp.Reason)) <= 0) {
-			return "", errorx.New(apiContracts.ErrAPI_Helpers_Generic, resp.Reason)
-		}
-		return "", apiContracts.ErrAPI_Helpers_Unknown
+		return "", apiError.ToError(serviceRegisterAPIErrors, apiContracts.ErrAPI_Helpers_Generic, apiContracts.ErrAPI_Helpers_Unknown)
 	}
 
 	return strings.Replace(resp.Secret, ":", "", -1), nil
 }
 
-func (thisRef service) CreateFullService(info apiContracts.ServiceRegistrationInfo, projectKey string, projectSecret string) (apiContracts.Service, errorx.Error) {
+func (thisRef service) CreateFullService(info apiContracts.ServiceRegistrationInfo, projectKey string, projectSecret string) (_ apiContracts.Service, errx errorx.Error) {
+	var op *operation
+	op, thisRef.apiClient = startClientOperation(thisRef.apiClient, "Service.CreateFullService")
+	defer op.end(&errx)
+
 	uid, err := thisRef.GenerateUID(projectKey, projectSecret)
 	if err != nil {
 		return apiContracts.Service{}, err
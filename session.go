@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+// TokenSource hands out the API token, the clients built with one ask it before every request.
+type TokenSource interface {
+	Token() (string, errorx.Error)
+}
+
+// staticToken is the token source of the clients built with a plain token.
+type staticToken string
+
+func (thisRef staticToken) Token() (string, errorx.Error) {
+	return string(thisRef), nil
+}
+
+// Session is one logged in account. It is a TokenSource that logs in again with the auth
+// hash once the token is as old as the cached auth response.
+type Session interface {
+	TokenSource
+
+	Authentication() apiContracts.Authentication
+	Refresh() (apiContracts.Authentication, errorx.Error)
+}
+
+// NewSession logs in with `authHash`, or with `password` when there is no auth hash.
+func NewSession(client Client, username string, password string, authHash string) (Session, errorx.Error) {
+	if authHash == "" {
+		authentication, errx := client.LoginWithPassword(username, password)
+		if errx != nil {
+			return nil, errx
+		}
+
+		authHash = authentication.AuthHash
+	}
+
+	session := &session{
+		client:   client,
+		username: username,
+		authHash: authHash,
+	}
+
+	if _, errx := session.Refresh(); errx != nil {
+		return nil, errx
+	}
+
+	return session, nil
+}
+
+type session struct {
+	client   Client
+	username string
+	authHash string
+
+	mutex          sync.Mutex
+	authentication apiContracts.Authentication
+	loginTime      time.Time
+}
+
+func (thisRef *session) Token() (string, errorx.Error) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	if time.Since(thisRef.loginTime) >= cachedAuthResponseExpireDuration {
+		if errx := thisRef.login(); errx != nil {
+			return "", errx
+		}
+	}
+
+	return thisRef.authentication.Token, nil
+}
+
+func (thisRef *session) Authentication() apiContracts.Authentication {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	return thisRef.authentication
+}
+
+// Refresh logs in again right away, for a token the API no longer accepts.
+func (thisRef *session) Refresh() (apiContracts.Authentication, errorx.Error) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	if errx := thisRef.login(); errx != nil {
+		return apiContracts.Authentication{}, errx
+	}
+
+	return thisRef.authentication, nil
+}
+
+// refreshToken logs in again unless another request already did since `refused` was handed
+// out, so the requests refused together share one login.
+func (thisRef *session) refreshToken(refused string) (string, errorx.Error) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	if thisRef.authentication.Token != refused {
+		return thisRef.authentication.Token, nil
+	}
+
+	if errx := thisRef.login(); errx != nil {
+		return "", errx
+	}
+
+	return thisRef.authentication.Token, nil
+}
+
+// authHashLogin is a Client that can log in without changing the auth response cached for
+// the clients without a session.
+type authHashLogin interface {
+	loginWithAuthHash(username string, authHash string) (apiContracts.Authentication, errorx.Error)
+}
+
+func (thisRef *session) login() errorx.Error {
+	var authentication apiContracts.Authentication
+	var errx errorx.Error
+	if client, ok := thisRef.client.(authHashLogin); ok {
+		authentication, errx = client.loginWithAuthHash(thisRef.username, thisRef.authHash)
+	} else {
+		authentication, errx = thisRef.client.LoginWithAuthHashIgnoreCache(thisRef.username, thisRef.authHash)
+	}
+	if errx != nil {
+		return errx
+	}
+
+	// the auth hash login does not always hand back the auth hash it was given
+	if authentication.AuthHash == "" {
+		authentication.AuthHash = thisRef.authHash
+	}
+
+	thisRef.authentication = authentication
+	thisRef.loginTime = time.Now()
+	return nil
+}
+
+// doHTTPRequestWithSession is doHTTPRequest sent once more with a new token when the API
+// refuses the one in `headers` and `tokens` is a session of NewSession, so the clients sharing
+// it recover from an expired token on their own.
+func doHTTPRequestWithSession(ctx context.Context, tokens TokenSource, transport http.RoundTripper, method string, headers map[string]string, url string, payload []byte, timeout time.Duration) (*http.Response, []byte, error) {
+	response, data, err := doHTTPRequest(ctx, transport, method, headers, url, payload, timeout)
+	if err != nil || !isTokenRefused(response, data) {
+		return response, data, err
+	}
+
+	shared, ok := tokens.(*session)
+	if !ok {
+		return response, data, err
+	}
+
+	token, errx := shared.refreshToken(headers["token"])
+	if errx != nil {
+		return response, data, err
+	}
+
+	headers["token"] = token
+
+	return doHTTPRequest(ctx, transport, method, headers, url, payload, timeout)
+}
+
+// doHTTPRequestToWriterWithSession is doHTTPRequestToWriter sent once more with a new token on a
+// 401, which comes before `writer` got anything so the retry starts over from the first byte.
+func doHTTPRequestToWriterWithSession(ctx context.Context, tokens TokenSource, transport http.RoundTripper, method string, headers map[string]string, url string, payload []byte, timeout time.Duration, writer io.Writer) (*http.Response, int64, error) {
+	response, written, err := doHTTPRequestToWriter(ctx, transport, method, headers, url, payload, timeout, writer)
+	if err == nil || written > 0 || response == nil || response.StatusCode != http.StatusUnauthorized {
+		return response, written, err
+	}
+
+	shared, ok := tokens.(*session)
+	if !ok {
+		return response, written, err
+	}
+
+	token, errx := shared.refreshToken(headers["token"])
+	if errx != nil {
+		return response, written, err
+	}
+
+	headers["token"] = token
+
+	return doHTTPRequestToWriter(ctx, transport, method, headers, url, payload, timeout, writer)
+}
+
+// isTokenRefused tells a 401 from the token clients and the "missing api token" reason of the
+// REST API apart from the other replies.
+func isTokenRefused(response *http.Response, data []byte) bool {
+	if response.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+
+	var reply struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	}
+	if json.Unmarshal(data, &reply) != nil {
+		return false
+	}
+
+	return reply.Status == apiContracts.API_ERROR_CODE_STATUS_FALSE && strings.Contains(strings.ToLower(reply.Reason), apiContracts.API_ERROR_CODE_REASON_MISSING_API_TOKEN)
+}
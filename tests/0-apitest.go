@@ -0,0 +1,30 @@
+package tests
+
+import (
+	"github.com/remoteit/sdk-go/apitest"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+// newFakeAPI starts an offline remote.it API where USER owns DEVICEID and its SERVICEID.
+func newFakeAPI() *apitest.Server {
+	server := apitest.NewServer()
+	server.AddAccount(apitest.Account{Username: USER, Password: PASS, APIKey: APIKEY})
+	server.AddDevice(apitest.Device{
+		UID:        DEVICEID,
+		Name:       "raspberrypi",
+		Type:       apiContracts.GetServiceType(apiContracts.DefaultServiceType, apiContracts.BulkServiceID, 0, 0),
+		HardwareID: MACHINEID,
+		Owner:      USER,
+	})
+	server.AddDevice(apitest.Device{
+		UID:        SERVICEID,
+		Name:       "ssh",
+		Type:       apiContracts.GetServiceType(apiContracts.DefaultServiceType, 28, 0, 0),
+		HardwareID: MACHINEID,
+		Owner:      USER,
+		Hostname:   "127.0.0.1",
+		Port:       22,
+	})
+
+	return server
+}
@@ -3,6 +3,6 @@ package tests
 const USER = "this-does-work-do-not-try"
 const PASS = "this-does-work-do-not-try"
 const APIKEY = "this-does-work-do-not-try"
-const SERVICEID = "this-does-work-do-not-try"
-const DEVICEID = "this-does-work-do-not-try"
-const MACHINEID = "this-does-work-do-not-try"
+const SERVICEID = "80:00:00:00:01:00:40:C6"
+const DEVICEID = "80:00:00:00:01:00:40:C5"
+const MACHINEID = "55eb0e08ddd14f8d8752a982e18bd4aa"
@@ -8,7 +8,10 @@ import (
 )
 
 func Test_Client_LoginUserAuthHash_Cache(t *testing.T) {
-	client := api.NewClient(apiContracts.DEFAULT_API_URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+	server := newFakeAPI()
+	defer server.Close()
+
+	client := api.NewClient(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
 
 	// get auth-hash + token
 	authentication1, errx := client.LoginWithPassword(USER, PASS)
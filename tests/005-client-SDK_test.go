@@ -0,0 +1,229 @@
+package tests
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+func Test_Client_SDK(t *testing.T) {
+	server := newFakeAPI()
+	defer server.Close()
+
+	sdk, errx := api.NewSDK(apiContracts.Profile{
+		APIURL:         server.URL,
+		GraphQLURL:     server.GraphQLURL,
+		RestoreURL:     server.RestoreURL,
+		CertificateURL: server.CertificateURL,
+		APIKey:         APIKEY,
+		Username:       USER,
+		Password:       PASS,
+	})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	firstToken, _ := sdk.Session().Token()
+	if firstToken == "" {
+		t.Error("Missing Token")
+		t.FailNow()
+	}
+
+	// 1. every client uses the session
+	if list, errx := sdk.Devices().ListAll(); errx != nil || len(list.Devices) != 2 {
+		t.Errorf("expected 2 devices, got %v %v", list.Devices, errx)
+	}
+	if _, errx := sdk.GraphQL().GetDeviceAndServiceNames(DEVICEID); errx != nil {
+		t.Error(errx)
+	}
+
+	// 2. another login does not leak into the session
+	other := api.NewClient(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+	if _, errx := other.LoginWithAuthHashIgnoreCache(USER, sdk.Session().Authentication().AuthHash); errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	other.ExpireAuthHash()
+
+	if token, _ := sdk.Session().Token(); token != firstToken {
+		t.Errorf("expected the session to keep its token")
+	}
+
+	// 3. a revoked token logs the session in again, then every client picks up the new one
+	server.RevokeTokens(USER)
+
+	if _, errx := sdk.Restore().Restore(DEVICEID, MACHINEID); errx != nil {
+		t.Error(errx)
+	}
+	if token, _ := sdk.Session().Token(); token == firstToken {
+		t.Error("expected a new token")
+	}
+
+	if list, errx := sdk.Devices().ListAll(); errx != nil || len(list.Devices) != 2 {
+		t.Errorf("expected 2 devices, got %v %v", list.Devices, errx)
+	}
+	if _, errx := sdk.GraphQL().GetServiceNamesByIDs([]string{SERVICEID}); errx != nil {
+		t.Error(errx)
+	}
+	if _, errx := sdk.Certificates().GenerateWithLocalKey(apiContracts.CertificateRequest{ServiceID: SERVICEID}, apiContracts.CertificateKeyECDSAP256); errx != nil {
+		t.Error(errx)
+	}
+
+	// 4. every client logs in again on its own
+	for _, call := range []func() errorx.Error{
+		func() (errx errorx.Error) { _, errx = sdk.Devices().ListAll(); return },
+		func() (errx errorx.Error) { _, errx = sdk.GraphQL().GetServiceNamesByIDs([]string{SERVICEID}); return },
+		func() (errx errorx.Error) {
+			_, errx = sdk.Certificates().GenerateWithLocalKey(apiContracts.CertificateRequest{ServiceID: SERVICEID}, apiContracts.CertificateKeyECDSAP256)
+			return
+		},
+	} {
+		server.RevokeTokens(USER)
+		if errx := call(); errx != nil {
+			t.Error(errx)
+		}
+	}
+
+	// 5. without credentials no token is sent, not even the one of another client
+	anonymous, errx := api.NewSDK(apiContracts.Profile{APIURL: server.URL, APIKey: APIKEY})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	if _, errx := api.NewClient(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT).LoginWithPassword(USER, PASS); errx != nil {
+		t.Error(errx)
+	}
+	if _, errx := anonymous.Devices().ListAll(); errx == nil {
+		t.Error("expected the devices to need a token")
+	}
+}
+
+// loginCounter is a transport counting the auth hash logins.
+type loginCounter struct {
+	mutex  sync.Mutex
+	logins int
+}
+
+func (thisRef *loginCounter) RoundTrip(request *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(request.URL.Path, "/user/login/authhash") {
+		thisRef.mutex.Lock()
+		thisRef.logins++
+		thisRef.mutex.Unlock()
+	}
+
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+func Test_Client_SDK_SharedSession(t *testing.T) {
+	server := newFakeAPI()
+	defer server.Close()
+
+	// 1. a plain client caches its login
+	plain := api.NewClient(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+	authentication, errx := plain.LoginWithPassword(USER, PASS)
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	cached, errx := plain.LoginWithAuthHashIgnoreCache(USER, authentication.AuthHash)
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	// 2. the logins of a session leave that cache alone
+	counter := &loginCounter{}
+	sdk, errx := api.NewSDKWithTransport(apiContracts.Profile{
+		APIURL:   server.URL,
+		APIKey:   APIKEY,
+		Username: USER,
+		AuthHash: authentication.AuthHash,
+	}, counter)
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	if _, errx := sdk.Session().Refresh(); errx != nil {
+		t.Error(errx)
+	}
+
+	if again, _ := plain.LoginWithAuthHash(USER, authentication.AuthHash); again.Token != cached.Token {
+		t.Errorf("expected the cached token %s, got %s", cached.Token, again.Token)
+	}
+
+	// 3. the requests refused together log in once
+	server.RevokeTokens(USER)
+	counter.logins = 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, errx := sdk.Devices().ListAll(); errx != nil {
+				t.Error(errx)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if counter.logins != 1 {
+		t.Errorf("expected 1 login, got %d", counter.logins)
+	}
+}
+
+// refusingDownload is a transport answering the first download with a 401 and the next ones with
+// `body`, keeping the tokens they came with.
+type refusingDownload struct {
+	body   string
+	tokens []string
+}
+
+func (thisRef *refusingDownload) RoundTrip(request *http.Request) (*http.Response, error) {
+	if !strings.Contains(request.URL.Path, "/project/provisioning/download/") {
+		return http.DefaultTransport.RoundTrip(request)
+	}
+
+	thisRef.tokens = append(thisRef.tokens, request.Header.Get("token"))
+	if len(thisRef.tokens) == 1 {
+		return &http.Response{StatusCode: http.StatusUnauthorized, Status: "401 Unauthorized", Body: ioutil.NopCloser(strings.NewReader("")), Request: request}, nil
+	}
+
+	return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: ioutil.NopCloser(strings.NewReader(thisRef.body)), Request: request}, nil
+}
+
+func Test_Client_SDK_SharedSession_Download(t *testing.T) {
+	server := newFakeAPI()
+	defer server.Close()
+
+	transport := &refusingDownload{body: "provisioning bundle contents"}
+	sdk, errx := api.NewSDKWithTransport(apiContracts.Profile{
+		APIURL:   server.URL,
+		APIKey:   APIKEY,
+		Username: USER,
+		Password: PASS,
+	}, transport)
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	var buffer bytes.Buffer
+	written, errx := sdk.Client().(api.Downloader).Download("/project/provisioning/download/REGKEY/hardware-id/", &buffer)
+	if errx != nil || written != int64(len(transport.body)) || buffer.String() != transport.body {
+		t.Errorf("unexpected download of %d bytes, %v", written, errx)
+		t.FailNow()
+	}
+
+	if len(transport.tokens) != 2 || transport.tokens[0] == transport.tokens[1] {
+		t.Errorf("expected the download to be retried once with a new token, got %v", transport.tokens)
+	}
+}
@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+func Test_Client_RateLimitGroupOf(t *testing.T) {
+	paths := map[string]apiContracts.RateLimitGroup{
+		"/apv/v27/user/login":                         apiContracts.RateLimitGroupAuth,
+		"/apv/v27/user/login/authhash":                apiContracts.RateLimitGroupAuth,
+		"/apv/v27/device/connect":                     apiContracts.RateLimitGroupConnect,
+		"/apv/v27/device/connect/stop":                apiContracts.RateLimitGroupConnect,
+		"/apv/v27/device/register":                    apiContracts.RateLimitGroupDevice,
+		"/apv/v27/developer/devices/transfer/x":       apiContracts.RateLimitGroupDevice,
+		"/apv/v27/bulk/registration/register":         apiContracts.RateLimitGroupDevice,
+		"/graphql/v1":                                 apiContracts.RateLimitGroupGraphQL,
+		"/v1/restore":                                 apiContracts.RateLimitGroupOther,
+		"/v1/certificate":                             apiContracts.RateLimitGroupOther,
+		"/apv/v27/developer/device/delete/registered": apiContracts.RateLimitGroupDevice,
+	}
+
+	for path, expected := range paths {
+		if group := api.RateLimitGroupOf(path); group != expected {
+			t.Errorf("%s: expected %s, got %s", path, expected, group)
+		}
+	}
+}
+
+func Test_Client_RateLimiter(t *testing.T) {
+	limiter := api.NewRateLimiter(apiContracts.RateLimits{
+		apiContracts.RateLimitGroupDevice:  {RequestsPerSecond: 20, Burst: 2},
+		apiContracts.RateLimitGroupConnect: {RequestsPerSecond: 0.1},
+	})
+
+	// 1. the burst goes out at once, then one request every 50ms
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := limiter.Wait(context.Background(), apiContracts.RateLimitGroupDevice); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected the last 2 requests to wait 100ms, waited %s", elapsed)
+	}
+
+	// 2. groups without a limit never wait
+	start = time.Now()
+	for i := 0; i < 100; i++ {
+		limiter.Wait(context.Background(), apiContracts.RateLimitGroupGraphQL)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("expected no wait, waited %s", elapsed)
+	}
+
+	// 3. a wait ends with its context
+	if err := limiter.Wait(context.Background(), apiContracts.RateLimitGroupConnect); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start = time.Now()
+	if err := limiter.Wait(ctx, apiContracts.RateLimitGroupConnect); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the wait to stop with the context, waited %s", elapsed)
+	}
+}
+
+func Test_Client_RateLimit_SDK(t *testing.T) {
+	server := newFakeAPI()
+	defer server.Close()
+
+	sdk, errx := api.NewSDK(apiContracts.Profile{
+		APIURL:     server.URL,
+		GraphQLURL: server.GraphQLURL,
+		APIKey:     APIKEY,
+		Username:   USER,
+		Password:   PASS,
+		RateLimits: apiContracts.RateLimits{
+			apiContracts.RateLimitGroupDevice: {RequestsPerSecond: 20, Burst: 1},
+		},
+	})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	// the device clients share one bucket, whatever goroutine calls them
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, errx := sdk.Devices().ListAll(); errx != nil {
+				t.Error(errx)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, errx := sdk.Services().GenerateUID("key", "secret"); errx != nil {
+				t.Error(errx)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("expected 6 requests at 20/s to take 250ms, took %s", elapsed)
+	}
+}
+
+func Test_Client_RateLimit_SDKContext(t *testing.T) {
+	server := newFakeAPI()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sdk, errx := api.NewSDKWithContext(ctx, apiContracts.Profile{
+		APIURL:   server.URL,
+		APIKey:   APIKEY,
+		Username: USER,
+		Password: PASS,
+		Timeout:  100 * time.Millisecond,
+		RateLimits: apiContracts.RateLimits{
+			apiContracts.RateLimitGroupDevice: {RequestsPerSecond: 5, Burst: 1},
+		},
+	}, nil, api.Instrumentation{})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	// 1. waiting 200ms for the bucket does not use up the 100ms timeout
+	for i := 0; i < 2; i++ {
+		if _, errx := sdk.Devices().ListAll(); errx != nil {
+			t.Errorf("request %d: %v", i, errx)
+		}
+	}
+
+	// 2. cancelling the context of the SDK ends the wait
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	if _, errx := sdk.Devices().ListAll(); errx == nil {
+		t.Error("expected the cancelled request to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("expected the wait to stop with the context, waited %s", elapsed)
+	}
+}
@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	api "github.com/remoteit/sdk-go"
+	"github.com/remoteit/sdk-go/apitest"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+type logRecord struct {
+	level string
+	msg   string
+	attrs map[string]interface{}
+}
+
+// recordingLogger is an api.Logger that keeps every record.
+type recordingLogger struct {
+	mutex   sync.Mutex
+	records []logRecord
+}
+
+func (thisRef *recordingLogger) Debug(msg string, args ...interface{}) {
+	thisRef.add("debug", msg, args)
+}
+
+func (thisRef *recordingLogger) Info(msg string, args ...interface{}) {
+	thisRef.add("info", msg, args)
+}
+
+func (thisRef *recordingLogger) Warn(msg string, args ...interface{}) {
+	thisRef.add("warn", msg, args)
+}
+
+func (thisRef *recordingLogger) Error(msg string, args ...interface{}) {
+	thisRef.add("error", msg, args)
+}
+
+func (thisRef *recordingLogger) add(level string, msg string, args []interface{}) {
+	attrs := map[string]interface{}{}
+	for i := 0; i+1 < len(args); i += 2 {
+		attrs[fmt.Sprint(args[i])] = args[i+1]
+	}
+
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	thisRef.records = append(thisRef.records, logRecord{level, msg, attrs})
+}
+
+func (thisRef *recordingLogger) find(level string, msg string, endpoint string) []logRecord {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	found := []logRecord{}
+	for _, record := range thisRef.records {
+		if record.level == level && record.msg == msg && strings.HasSuffix(fmt.Sprint(record.attrs["endpoint"]), endpoint) {
+			found = append(found, record)
+		}
+	}
+
+	return found
+}
+
+func Test_Client_Logging(t *testing.T) {
+	server := newFakeAPI()
+	defer server.Close()
+
+	logger := &recordingLogger{}
+	client := api.NewClientWithTransport(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT, api.NewLoggingTransport(logger, nil))
+
+	authentication, errx := client.LoginWithPassword(USER, PASS)
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	if _, errx := client.LoginWithAuthHashIgnoreCache(USER, authentication.AuthHash); errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	// 1. one record per request
+	requests := logger.find("info", "api request", "/user/login")
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 login record, got %v", logger.records)
+	}
+	for _, key := range []string{"method", "endpoint", "status", "duration"} {
+		if _, ok := requests[0].attrs[key]; !ok {
+			t.Errorf("expected %s in %v", key, requests[0].attrs)
+		}
+	}
+	if requests[0].attrs["method"] != "POST" || requests[0].attrs["status"] != 200 {
+		t.Errorf("unexpected record %v", requests[0].attrs)
+	}
+
+	// 2. the bodies are only logged redacted
+	bodies := append(logger.find("debug", "api request body", "/user/login"), logger.find("debug", "api response body", "/user/login/authhash")...)
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 body records, got %v", logger.records)
+	}
+	for _, record := range bodies {
+		body := fmt.Sprint(record.attrs["body"])
+		if !strings.Contains(body, apiContracts.Redacted) || strings.Contains(body, `"password":"`+PASS) {
+			t.Errorf("expected a redacted body, got %s", body)
+		}
+	}
+	for _, record := range logger.records {
+		for _, secret := range []string{authentication.AuthHash, authentication.Token} {
+			if strings.Contains(fmt.Sprint(record.attrs), secret) {
+				t.Errorf("%q leaked into %v", secret, record.attrs)
+			}
+		}
+	}
+
+	// 3. the refused requests are warnings with the API reason
+	server.Fail("/device/list/all", apitest.Failure{Reason: "[0999] maintenance", Times: 1})
+	api.NewDevice(client).ListAll()
+
+	warnings := logger.find("warn", "api request", "/device/list/all")
+	if len(warnings) != 1 || warnings[0].attrs["error"] != "[0999] maintenance" {
+		t.Errorf("expected a warning with the reason, got %v", logger.records)
+	}
+
+	// 4. the secrets in the paths are redacted
+	if _, errx := api.NewService(client).GenerateUID("PROJECT-KEY", "PROJECT-SECRET"); errx != nil {
+		t.Error(errx)
+	}
+	if generated := logger.find("info", "api request", "/device/address/"+apiContracts.Redacted+"/"+apiContracts.Redacted); len(generated) != 1 {
+		t.Errorf("expected a redacted endpoint, got %v", logger.records)
+	}
+
+	// 5. no response at all is an error
+	server.Close()
+	api.NewDevice(client).ListAll()
+
+	if errors := logger.find("error", "api request", "/device/list/all"); len(errors) != 1 || errors[0].attrs["error"] == "" {
+		t.Errorf("expected an error record, got %v", logger.records)
+	}
+}
+
+func Test_Client_Logging_UnknownLength(t *testing.T) {
+	large := bytes.Repeat([]byte("0123456789abcdef"), 2<<20/16)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// flushing first leaves the length unknown, the reply is chunked
+		w.Header().Set("Content-Type", "text/plain")
+		w.(http.Flusher).Flush()
+
+		if r.URL.Path == "/large" {
+			w.Write(large)
+		} else {
+			w.Write([]byte(`{"status":"true"}`))
+		}
+	}))
+	defer server.Close()
+
+	logger := &recordingLogger{}
+	client := &http.Client{Transport: api.NewLoggingTransport(logger, nil)}
+
+	for path, expected := range map[string][]byte{"/large": large, "/small": []byte(`{"status":"true"}`)} {
+		response, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil || !bytes.Equal(body, expected) {
+			t.Errorf("expected the whole %s body, got %d bytes %v", path, len(body), err)
+		}
+	}
+
+	if bodies := logger.find("debug", "api response body", "/large"); len(bodies) != 0 {
+		t.Errorf("expected the large body not to be logged, got %d records", len(bodies))
+	}
+	if bodies := logger.find("debug", "api response body", "/small"); len(bodies) != 1 {
+		t.Errorf("expected the small body to be logged, got %v", logger.records)
+	}
+}
@@ -0,0 +1,234 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/remoteit/sdk-go"
+	"github.com/remoteit/sdk-go/apitest"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+type recordedSpan struct {
+	name       string
+	parent     *recordedSpan
+	attributes map[string]interface{}
+	errors     []error
+	ended      bool
+}
+
+func (thisRef *recordedSpan) SetAttribute(key string, value interface{}) {
+	thisRef.attributes[key] = value
+}
+
+func (thisRef *recordedSpan) RecordError(err error) {
+	thisRef.errors = append(thisRef.errors, err)
+}
+
+func (thisRef *recordedSpan) End() {
+	thisRef.ended = true
+}
+
+type spanKey struct{}
+
+// recordingTracer is an api.Tracer keeping every span with its parent.
+type recordingTracer struct {
+	mutex sync.Mutex
+	spans []*recordedSpan
+}
+
+func (thisRef *recordingTracer) Start(ctx context.Context, name string) (context.Context, api.Span) {
+	parent, _ := ctx.Value(spanKey{}).(*recordedSpan)
+	span := &recordedSpan{name: name, parent: parent, attributes: map[string]interface{}{}}
+
+	thisRef.mutex.Lock()
+	thisRef.spans = append(thisRef.spans, span)
+	thisRef.mutex.Unlock()
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (thisRef *recordingTracer) children(parent *recordedSpan) []string {
+	names := []string{}
+	for _, span := range thisRef.spans {
+		if span.parent == parent {
+			names = append(names, span.name)
+		}
+	}
+
+	return names
+}
+
+func (thisRef *recordingTracer) find(name string) *recordedSpan {
+	for _, span := range thisRef.spans {
+		if span.name == name {
+			return span
+		}
+	}
+
+	return nil
+}
+
+// recordingMetrics is an api.Metrics summing every value by name and labels.
+type recordingMetrics struct {
+	mutex  sync.Mutex
+	values map[string]float64
+	counts map[string]int
+}
+
+func (thisRef *recordingMetrics) AddCounter(name string, value float64, labels map[string]string) {
+	thisRef.record(name, value, labels)
+}
+
+func (thisRef *recordingMetrics) ObserveHistogram(name string, value float64, labels map[string]string) {
+	thisRef.record(name, value, labels)
+}
+
+func (thisRef *recordingMetrics) record(name string, value float64, labels map[string]string) {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	key := metricKey(name, labels)
+	thisRef.values[key] += value
+	thisRef.counts[key]++
+}
+
+func metricKey(name string, labels map[string]string) string {
+	keys := []string{}
+	for _, label := range []string{"operation", "code", "group", "method", "status"} {
+		if value, ok := labels[label]; ok {
+			keys = append(keys, label+"="+value)
+		}
+	}
+
+	return name + "{" + strings.Join(keys, ",") + "}"
+}
+
+func Test_Client_Instrumentation(t *testing.T) {
+	server := newFakeAPI()
+	defer server.Close()
+
+	tracer := &recordingTracer{}
+	metrics := &recordingMetrics{values: map[string]float64{}, counts: map[string]int{}}
+
+	sdk, errx := api.NewSDKWithInstrumentation(apiContracts.Profile{
+		APIURL:     server.URL,
+		GraphQLURL: server.GraphQLURL,
+		APIKey:     APIKEY,
+		Username:   USER,
+		Password:   PASS,
+	}, nil, api.Instrumentation{Tracer: tracer, Metrics: metrics})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	// 1. the nested operations and their HTTP calls are child spans
+	_, errx = sdk.Services().CreateFullService(apiContracts.ServiceRegistrationInfo{
+		Name:             "web",
+		ServiceType:      apiContracts.GetServiceType(apiContracts.DefaultServiceType, 7, 0, 0),
+		ServiceTypeAsInt: 7,
+		HardwareID:       MACHINEID,
+	}, "key", "secret")
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	root := tracer.find("Service.CreateFullService")
+	if root == nil || root.parent != nil || !root.ended {
+		t.Fatalf("expected an ended root span, got %+v", root)
+	}
+	if children := strings.Join(tracer.children(root), ","); children != "Service.GenerateUID,Service.Create,Service.Register" {
+		t.Errorf("unexpected children %s", children)
+	}
+	for _, name := range tracer.children(root) {
+		child := tracer.find(name)
+		if calls := tracer.children(child); len(calls) != 1 || calls[0] != "HTTP POST" && calls[0] != "HTTP GET" {
+			t.Errorf("expected one HTTP span under %s, got %v", name, calls)
+		}
+	}
+
+	// the HTTP spans carry the endpoint group, not the path with the project key and secret
+	generateUID := tracer.find("Service.GenerateUID")
+	for _, span := range tracer.spans {
+		if span.parent == generateUID && span.attributes["http.route"] != "device" {
+			t.Errorf("expected the device route, got %v", span.attributes)
+		}
+		if strings.Contains(fmt.Sprint(span.attributes), "secret") {
+			t.Errorf("expected no secret in the span attributes, got %v", span.attributes)
+		}
+	}
+
+	if metrics.counts[metricKey(apiContracts.METRIC_API_OPERATION_DURATION, map[string]string{"operation": "Service.CreateFullService"})] != 1 {
+		t.Errorf("expected the operation duration, got %v", metrics.counts)
+	}
+	if metrics.counts[metricKey(apiContracts.METRIC_API_REQUEST_DURATION, map[string]string{"group": "device", "method": "POST", "status": "200"})] != 2 {
+		t.Errorf("expected 2 device POST durations, got %v", metrics.counts)
+	}
+
+	// 2. the token clients report too
+	if _, errx := sdk.GraphQL().GetApplicationType(SERVICEID); errx != nil {
+		t.Error(errx)
+	}
+	if span := tracer.find("GraphQL.GetApplicationType"); span == nil || len(tracer.children(span)) == 0 {
+		t.Errorf("expected a GraphQL span with children, got %+v", span)
+	}
+
+	// 3. errors are counted by code
+	server.Fail("/device/list/all", apitest.Failure{Reason: "[0999] maintenance", Times: 1})
+	_, errx = sdk.Devices().ListAll()
+
+	var apiError *apiContracts.Error
+	if !errors.As(errx, &apiError) {
+		t.Fatalf("expected an API error, got %v", errx)
+	}
+	if span := tracer.find("Device.ListAll"); span == nil || len(span.errors) != 1 {
+		t.Errorf("expected the error on the span, got %+v", span)
+	}
+
+	errorsKey := metricKey(apiContracts.METRIC_API_ERRORS, map[string]string{"operation": "Device.ListAll", "code": strconv.Itoa(apiError.Code())})
+	if metrics.values[errorsKey] != 1 {
+		t.Errorf("expected %s to be 1, got %v", errorsKey, metrics.values)
+	}
+}
+
+func Test_Client_Instrumentation_Retries(t *testing.T) {
+	server := newFakeAPI()
+	defer server.Close()
+	server.AddBulkProject(apitest.BulkProject{
+		RegistrationKey: "BULKKEY",
+		Owner:           USER,
+		Templates:       []apitest.BulkTemplate{{ID: "template-ssh", Type: 28, Hostname: "127.0.0.1", Port: "22"}},
+		PendingPolls:    2,
+	})
+
+	metrics := &recordingMetrics{values: map[string]float64{}, counts: map[string]int{}}
+
+	sdk, errx := api.NewSDKWithInstrumentation(apiContracts.Profile{APIURL: server.URL, APIKey: APIKEY}, nil, api.Instrumentation{Metrics: metrics})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	_, errx = sdk.AutoRegistration().AutoRegisterIfNeeded(apiContracts.AutoRegistrationRequest{
+		RegistrationKey: "BULKKEY",
+		HardwareID:      "retries-hardware-id",
+		InitialBackoff:  time.Millisecond,
+	})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	retriesKey := metricKey(apiContracts.METRIC_API_RETRIES, map[string]string{"operation": "AutoRegistration.AutoRegisterIfNeeded"})
+	if metrics.values[retriesKey] != 2 {
+		t.Errorf("expected 2 retries, got %v", metrics.values)
+	}
+}
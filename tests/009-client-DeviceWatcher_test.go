@@ -0,0 +1,199 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/remoteit/sdk-go"
+	"github.com/remoteit/sdk-go/apitest"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+func describeEvents(events []apiContracts.DeviceEvent) string {
+	described := []string{}
+	for _, event := range events {
+		described = append(described, event.Type.String()+" "+event.Device.DeviceAddress)
+	}
+
+	return strings.Join(described, ",")
+}
+
+func Test_Client_DiffDevices(t *testing.T) {
+	previous := []apiContracts.Device{
+		{DeviceAddress: "A", DeviceAlias: "a", DeviceState: apiContracts.DEVICE_STATE_ACTIVE, OwnerUserName: USER},
+		{DeviceAddress: "B", DeviceAlias: "b", DeviceState: apiContracts.DEVICE_STATE_ACTIVE, OwnerUserName: USER},
+		{DeviceAddress: "C", DeviceAlias: "c", DeviceState: apiContracts.DEVICE_STATE_ACTIVE, OwnerUserName: USER},
+	}
+	current := []apiContracts.Device{
+		{DeviceAddress: "D", DeviceAlias: "d", DeviceState: apiContracts.DEVICE_STATE_ACTIVE, OwnerUserName: USER},
+		{DeviceAddress: "B", DeviceAlias: "renamed", DeviceState: apiContracts.DEVICE_STATE_INACTIVE, OwnerUserName: USER},
+		{DeviceAddress: "A", DeviceAlias: "a", DeviceState: apiContracts.DEVICE_STATE_ACTIVE, OwnerUserName: "someone-else"},
+	}
+
+	events := apiContracts.DiffDevices(previous, current, time.Now())
+	if described := describeEvents(events); described != "owner changed A,renamed B,state changed B,added D,removed C" {
+		t.Fatalf("unexpected events %s", described)
+	}
+	if events[1].Previous.DeviceAlias != "b" || events[1].Device.DeviceAlias != "renamed" {
+		t.Errorf("expected the previous and current device, got %+v", events[1])
+	}
+
+	if events := apiContracts.DiffDevices(current, current, time.Now()); len(events) != 0 {
+		t.Errorf("expected no events, got %s", describeEvents(events))
+	}
+}
+
+func Test_Client_DeviceWatcher(t *testing.T) {
+	server := newFakeAPI()
+	defer server.Close()
+
+	sdk, errx := api.NewSDK(apiContracts.Profile{APIURL: server.URL, APIKey: APIKEY, Username: USER, Password: PASS})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	// the background polls never run, `PollNow` drives the watcher
+	watcher := api.NewDeviceWatcher(sdk.Devices(), apiContracts.DeviceWatcherOptions{Interval: time.Hour})
+	if errx := watcher.Start(); errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	if len(watcher.Devices()) != 2 {
+		t.Errorf("expected the first listing, got %v", watcher.Devices())
+	}
+
+	// 1. the first listing is not reported
+	if errx := watcher.PollNow(); errx != nil {
+		t.Error(errx)
+	}
+	if len(watcher.Events()) != 0 {
+		t.Errorf("expected no events, got %d", len(watcher.Events()))
+	}
+
+	// 2. the changes are
+	service, _ := server.Device(SERVICEID)
+	service.Name = "ssh-renamed"
+	service.Active = true
+	server.AddDevice(service)
+	added := server.AddDevice(apitest.Device{Name: "web", Owner: USER})
+	if errx := sdk.Devices().Unregister(DEVICEID); errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	if errx := watcher.PollNow(); errx != nil {
+		t.Error(errx)
+	}
+
+	events := []apiContracts.DeviceEvent{}
+	for len(watcher.Events()) > 0 {
+		events = append(events, <-watcher.Events())
+	}
+
+	expected := "added " + added.UID + ",renamed " + SERVICEID + ",state changed " + SERVICEID + ",removed " + DEVICEID
+	if described := describeEvents(events); described != expected {
+		t.Fatalf("expected %s, got %s", expected, described)
+	}
+	if events[2].Device.DeviceState != apiContracts.DEVICE_STATE_ACTIVE || events[2].Previous.DeviceState != apiContracts.DEVICE_STATE_INACTIVE {
+		t.Errorf("unexpected state change %+v", events[2])
+	}
+
+	// 3. a failed listing is reported by the next successful one
+	server.Fail("/device/list/all", apitest.Failure{Reason: "[0999] maintenance", Times: 1})
+	server.AddDevice(apitest.Device{Name: "later", Owner: USER})
+	if errx := watcher.PollNow(); errx == nil {
+		t.Error("expected an error")
+	}
+	if errx := watcher.PollNow(); errx != nil {
+		t.Error(errx)
+	}
+	if event := <-watcher.Events(); event.Type != apiContracts.DeviceAdded || event.Device.DeviceAlias != "later" {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	// 4. stopping closes the events
+	closing := watcher.Events()
+	watcher.Stop()
+	if _, ok := <-closing; ok {
+		t.Error("expected the events to be closed")
+	}
+}
+
+func Test_Client_DeviceWatcher_Backpressure(t *testing.T) {
+	server := newFakeAPI()
+	defer server.Close()
+
+	sdk, errx := api.NewSDK(apiContracts.Profile{APIURL: server.URL, APIKey: APIKEY, Username: USER, Password: PASS})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	// 1. dropping the oldest events keeps the newest ones
+	dropped := []apiContracts.DeviceEvent{}
+	watcher := api.NewDeviceWatcher(sdk.Devices(), apiContracts.DeviceWatcherOptions{
+		Interval:     time.Hour,
+		BufferSize:   1,
+		Backpressure: apiContracts.DeviceWatcherDropOldest,
+		OnDropped:    func(event apiContracts.DeviceEvent) { dropped = append(dropped, event) },
+	})
+	if errx := watcher.Start(); errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	server.AddDevice(apitest.Device{UID: "80:00:00:00:01:00:00:01", Owner: USER})
+	server.AddDevice(apitest.Device{UID: "80:00:00:00:01:00:00:02", Owner: USER})
+	if errx := watcher.PollNow(); errx != nil {
+		t.Error(errx)
+	}
+
+	if len(dropped) != 1 || dropped[0].Device.DeviceAddress != "80:00:00:00:01:00:00:01" {
+		t.Errorf("expected the first event to be dropped, got %v", dropped)
+	}
+	if event := <-watcher.Events(); event.Device.DeviceAddress != "80:00:00:00:01:00:00:02" {
+		t.Errorf("expected the newest event, got %+v", event)
+	}
+	watcher.Stop()
+
+	// 2. blocking waits for the reader, stopping releases it
+	errors := make(chan errorx.Error, 1)
+	watcher = api.NewDeviceWatcher(sdk.Devices(), apiContracts.DeviceWatcherOptions{Interval: time.Hour, BufferSize: 1})
+	if errx := watcher.Start(); errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	server.AddDevice(apitest.Device{UID: "80:00:00:00:01:00:00:03", Owner: USER})
+	server.AddDevice(apitest.Device{UID: "80:00:00:00:01:00:00:04", Owner: USER})
+	go func() { errors <- watcher.PollNow() }()
+
+	select {
+	case <-errors:
+		t.Fatal("expected the poll to wait for the reader")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if event := <-watcher.Events(); event.Device.DeviceAddress != "80:00:00:00:01:00:00:03" {
+		t.Errorf("expected the oldest event first, got %+v", event)
+	}
+	if errx := <-errors; errx != nil {
+		t.Error(errx)
+	}
+	if event := <-watcher.Events(); event.Device.DeviceAddress != "80:00:00:00:01:00:00:04" {
+		t.Errorf("expected the next event, got %+v", event)
+	}
+
+	server.AddDevice(apitest.Device{UID: "80:00:00:00:01:00:00:05", Owner: USER})
+	server.AddDevice(apitest.Device{UID: "80:00:00:00:01:00:00:06", Owner: USER})
+	go func() { errors <- watcher.PollNow() }()
+	time.Sleep(50 * time.Millisecond)
+
+	watcher.Stop()
+	if errx := <-errors; errx != nil {
+		t.Error(errx)
+	}
+}
@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+const WEBHOOK_SECRET = "webhook-secret"
+
+func sendWebhook(receiver http.Handler, body string, headers map[string]string) int {
+	request := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	recorder := httptest.NewRecorder()
+	receiver.ServeHTTP(recorder, request)
+
+	return recorder.Code
+}
+
+// webhookTimestamp is the timestamp field of a notification sent `ago`.
+func webhookTimestamp(ago time.Duration) string {
+	return `"timestamp":"` + time.Now().Add(-ago).UTC().Format(time.RFC3339) + `"`
+}
+
+func signed(body string) map[string]string {
+	return map[string]string{apiContracts.WEBHOOK_SIGNATURE_HEADER: apiContracts.WebhookSignature(WEBHOOK_SECRET, []byte(body))}
+}
+
+func Test_Client_Webhook(t *testing.T) {
+	errs := []errorx.Error{}
+	receiver := api.NewWebhookReceiver(apiContracts.WebhookReceiverOptions{
+		Secret:  WEBHOOK_SECRET,
+		OnError: func(errx errorx.Error) { errs = append(errs, errx) },
+	})
+
+	states := []apiContracts.DeviceStateEvent{}
+	receiver.OnDeviceState(func(event apiContracts.DeviceStateEvent) errorx.Error {
+		states = append(states, event)
+		return nil
+	})
+	connections := []apiContracts.ConnectionEvent{}
+	receiver.OnConnection(func(event apiContracts.ConnectionEvent) errorx.Error {
+		connections = append(connections, event)
+		return nil
+	})
+	all := []apiContracts.WebhookEvent{}
+	receiver.OnEvent(func(event apiContracts.WebhookEvent) errorx.Error {
+		all = append(all, event)
+		return nil
+	})
+
+	// 1. a signed state change is decoded
+	stateChange := `{"id":"event-1","type":"DEVICE_STATE",` + webhookTimestamp(0) + `,"data":{"deviceaddress":"` + SERVICEID + `","devicealias":"ssh","state":"inactive"}}`
+	if code := sendWebhook(receiver, stateChange, signed(stateChange)); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if len(states) != 1 || states[0].ID != "event-1" || states[0].DeviceAddress != SERVICEID || states[0].State != apiContracts.DEVICE_STATE_INACTIVE || states[0].Timestamp.IsZero() {
+		t.Errorf("unexpected state events %+v", states)
+	}
+
+	// 2. the same event sent again is only acknowledged
+	if code := sendWebhook(receiver, stateChange, signed(stateChange)); code != http.StatusOK || len(states) != 1 {
+		t.Errorf("expected the duplicate to be dropped, got %d and %d events", code, len(states))
+	}
+
+	// 3. connections tell connects from disconnects
+	for _, body := range []string{
+		`{"id":"event-2","type":"DEVICE_CONNECT",` + webhookTimestamp(0) + `,"data":{"deviceaddress":"` + SERVICEID + `","connectionid":"C1","username":"` + USER + `"}}`,
+		`{"id":"event-3","type":"DEVICE_DISCONNECT",` + webhookTimestamp(0) + `,"data":{"deviceaddress":"` + SERVICEID + `","connectionid":"C1"}}`,
+	} {
+		if code := sendWebhook(receiver, body, signed(body)); code != http.StatusOK {
+			t.Errorf("expected 200, got %d", code)
+		}
+	}
+	if len(connections) != 2 || !connections[0].Connected || connections[0].UserName != USER || connections[1].Connected || connections[1].ConnectionID != "C1" {
+		t.Errorf("unexpected connection events %+v", connections)
+	}
+
+	// 4. unknown types only reach the OnEvent handlers
+	unknown := `{"id":"event-4","type":"DEVICE_SHARE",` + webhookTimestamp(0) + `,"data":{"deviceaddress":"` + SERVICEID + `"}}`
+	if code := sendWebhook(receiver, unknown, signed(unknown)); code != http.StatusOK {
+		t.Errorf("expected 200, got %d", code)
+	}
+	if len(all) != 4 || all[3].Type != "DEVICE_SHARE" || len(states) != 1 || len(connections) != 2 {
+		t.Errorf("unexpected events %+v", all)
+	}
+
+	// 5. the rejected notifications
+	forged := strings.Replace(stateChange, "event-1", "event-5", 1)
+	malformed := `{"id":"event-6","type":"DEVICE_STATE",` + webhookTimestamp(0) + `,"data":"inactive"}`
+	stale := `{"id":"event-7","type":"DEVICE_STATE",` + webhookTimestamp(time.Hour) + `,"data":{"deviceaddress":"` + SERVICEID + `","state":"active"}}`
+	future := `{"id":"event-8","type":"DEVICE_STATE",` + webhookTimestamp(-time.Hour) + `,"data":{"deviceaddress":"` + SERVICEID + `","state":"active"}}`
+	undated := `{"id":"event-9","type":"DEVICE_STATE","data":{"deviceaddress":"` + SERVICEID + `","state":"active"}}`
+	for _, rejected := range []struct {
+		body    string
+		headers map[string]string
+		code    int
+		errx    errorx.Error
+	}{
+		{forged, signed(stateChange), http.StatusUnauthorized, apiContracts.ErrWebhook_InvalidSignature},
+		{forged, map[string]string{apiContracts.WEBHOOK_SECRET_HEADER: "wrong"}, http.StatusUnauthorized, apiContracts.ErrWebhook_InvalidSignature},
+		{forged, map[string]string{apiContracts.WEBHOOK_SECRET_HEADER: WEBHOOK_SECRET}, http.StatusUnauthorized, apiContracts.ErrWebhook_InvalidSignature},
+		{forged, nil, http.StatusUnauthorized, apiContracts.ErrWebhook_InvalidSignature},
+		{`{"id":`, signed(`{"id":`), http.StatusBadRequest, apiContracts.ErrWebhook_CantDecode},
+		{malformed, signed(malformed), http.StatusBadRequest, apiContracts.ErrWebhook_CantDecode},
+		{stale, signed(stale), http.StatusUnauthorized, apiContracts.ErrWebhook_StaleTimestamp},
+		{future, signed(future), http.StatusUnauthorized, apiContracts.ErrWebhook_StaleTimestamp},
+		{undated, signed(undated), http.StatusUnauthorized, apiContracts.ErrWebhook_StaleTimestamp},
+	} {
+		errs = nil
+		if code := sendWebhook(receiver, rejected.body, rejected.headers); code != rejected.code {
+			t.Errorf("expected %d for %s, got %d", rejected.code, rejected.body, code)
+		}
+		if len(errs) != 1 || errs[0].Code() != rejected.errx.Code() {
+			t.Errorf("expected %v for %s, got %v", rejected.errx, rejected.body, errs)
+		}
+	}
+	if len(all) != 4 {
+		t.Errorf("expected no more events, got %+v", all)
+	}
+
+	recorder := httptest.NewRecorder()
+	receiver.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/webhook", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", recorder.Code)
+	}
+}
+
+func Test_Client_Webhook_SecretHeader(t *testing.T) {
+	receiver := api.NewWebhookReceiver(apiContracts.WebhookReceiverOptions{
+		Secret:            WEBHOOK_SECRET,
+		AllowSecretHeader: true,
+	})
+
+	calls := 0
+	receiver.OnEvent(func(event apiContracts.WebhookEvent) errorx.Error {
+		calls++
+		return nil
+	})
+
+	// 1. the shared secret is accepted once allowed
+	body := `{"id":"event-1","type":"DEVICE_STATE",` + webhookTimestamp(0) + `,"data":{"deviceaddress":"` + SERVICEID + `","state":"active"}}`
+	if code := sendWebhook(receiver, body, map[string]string{apiContracts.WEBHOOK_SECRET_HEADER: WEBHOOK_SECRET}); code != http.StatusOK || calls != 1 {
+		t.Errorf("expected 200 and 1 call, got %d and %d", code, calls)
+	}
+	if code := sendWebhook(receiver, body, map[string]string{apiContracts.WEBHOOK_SECRET_HEADER: "wrong"}); code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", code)
+	}
+
+	// 2. a notification without an ID sent again is dropped too
+	anonymous := `{"type":"DEVICE_STATE",` + webhookTimestamp(0) + `,"data":{"deviceaddress":"` + SERVICEID + `","state":"inactive"}}`
+	for i := 0; i < 2; i++ {
+		if code := sendWebhook(receiver, anonymous, map[string]string{apiContracts.WEBHOOK_SECRET_HEADER: WEBHOOK_SECRET}); code != http.StatusOK {
+			t.Errorf("expected 200, got %d", code)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected the notification without an ID to be handled once, got %d calls", calls-1)
+	}
+}
+
+func Test_Client_Webhook_HandlerError(t *testing.T) {
+	receiver := api.NewWebhookReceiver(apiContracts.WebhookReceiverOptions{Secret: WEBHOOK_SECRET})
+
+	calls := 0
+	receiver.OnDeviceState(func(event apiContracts.DeviceStateEvent) errorx.Error {
+		calls++
+		if calls == 1 {
+			return apiContracts.NewReasonError(apiContracts.ErrAPI_Device_Generic, "busy")
+		}
+		return nil
+	})
+
+	// a failed notification is handled again when it is sent again
+	body := `{"id":"event-1","type":"DEVICE_STATE",` + webhookTimestamp(0) + `,"data":{"deviceaddress":"` + SERVICEID + `","state":"active"}}`
+	if code := sendWebhook(receiver, body, signed(body)); code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", code)
+	}
+	if code := sendWebhook(receiver, body, signed(body)); code != http.StatusOK {
+		t.Errorf("expected 200, got %d", code)
+	}
+	if code := sendWebhook(receiver, body, signed(body)); code != http.StatusOK || calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func Test_Client_Webhook_NoSecret(t *testing.T) {
+	body := `{"id":"event-1","type":"DEVICE_STATE",` + webhookTimestamp(0) + `,"data":{"deviceaddress":"` + SERVICEID + `","state":"active"}}`
+
+	// 1. without a secret every notification is refused
+	errs := []errorx.Error{}
+	receiver := api.NewWebhookReceiver(apiContracts.WebhookReceiverOptions{
+		OnError: func(errx errorx.Error) { errs = append(errs, errx) },
+	})
+	if code := sendWebhook(receiver, body, signed(body)); code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", code)
+	}
+	if len(errs) != 1 || errs[0].Code() != apiContracts.ErrWebhook_NoSecret.Code() {
+		t.Errorf("expected %v, got %v", apiContracts.ErrWebhook_NoSecret, errs)
+	}
+
+	// 2. unless the receiver is insecure on purpose, the timestamp is still checked
+	receiver = api.NewWebhookReceiver(apiContracts.WebhookReceiverOptions{Insecure: true})
+	if code := sendWebhook(receiver, body, nil); code != http.StatusOK {
+		t.Errorf("expected 200, got %d", code)
+	}
+
+	stale := `{"id":"event-2","type":"DEVICE_STATE",` + webhookTimestamp(time.Hour) + `,"data":{"deviceaddress":"` + SERVICEID + `","state":"active"}}`
+	if code := sendWebhook(receiver, stale, nil); code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", code)
+	}
+}
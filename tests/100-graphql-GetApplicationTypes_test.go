@@ -9,10 +9,13 @@ import (
 )
 
 func Test_GraphQL_GetApplicationTypes(t *testing.T) {
-	client := api.NewClient(apiContracts.DEFAULT_API_URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+	server := newFakeAPI()
+	defer server.Close()
+
+	client := api.NewClient(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
 	authentication, _ := client.LoginWithPassword(USER, PASS)
 
-	graphQLClient := api.NewGraphQLClient(apiContracts.DEFAULT_API_GRAPHQL_URL, authentication.Token, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+	graphQLClient := api.NewGraphQLClient(server.GraphQLURL, authentication.Token, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
 
 	applicationTypes, errx := graphQLClient.GetApplicationTypes()
 	if errx != nil {
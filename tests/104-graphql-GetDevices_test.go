@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"testing"
+
+	api "github.com/remoteit/sdk-go"
+	"github.com/remoteit/sdk-go/apitest"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+func Test_GraphQL_GetDevices(t *testing.T) {
+	server := newFakeAPI()
+	defer server.Close()
+
+	// more than a page of devices
+	for i := 0; i < 600; i++ {
+		server.AddDevice(apitest.Device{
+			Name:  "web",
+			Type:  apiContracts.GetServiceType(apiContracts.DefaultServiceType, 7, 0, 0),
+			Owner: USER,
+		})
+	}
+
+	sdk, errx := api.NewSDK(apiContracts.Profile{
+		APIURL:     server.URL,
+		GraphQLURL: server.GraphQLURL,
+		APIKey:     APIKEY,
+		Username:   USER,
+		Password:   PASS,
+	})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	connection, errx := sdk.Proxies().Create(apiContracts.CreateProxyRequest{DeviceAddress: SERVICEID})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	devices, errx := sdk.GraphQL().GetDevices()
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	if len(devices) != 601 {
+		t.Errorf("expected 601 devices, got %d", len(devices))
+	}
+
+	var device apiContracts.ListedDevice
+	for _, listed := range devices {
+		if listed.ID == DEVICEID {
+			device = listed
+		}
+	}
+
+	if device.Owner != USER || device.State != apiContracts.DEVICE_STATE_INACTIVE || len(device.Services) != 2 {
+		t.Errorf("unexpected device %+v", device)
+		t.FailNow()
+	}
+
+	service := device.Services[1]
+	if service.ID != SERVICEID || service.ApplicationType != 28 {
+		t.Errorf("unexpected service %+v", service)
+	}
+	if len(service.Connections) != 1 || service.Connections[0] != connection.Connection.ConnectionID {
+		t.Errorf("expected the connection %s, got %v", connection.Connection.ConnectionID, service.Connections)
+	}
+}
@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+const restoreConfigSample = `{
+	"device": {"uid": "80:00:00:00:01:00:40:C5", "secret": "DEVICESECRET", "name": "raspberrypi", "hardwareId": "55eb0e08ddd14f8d8752a982e18bd4aa"},
+	"services": [
+		{"uid": "80:00:00:00:01:00:40:C6", "secret": "SSHSECRET", "name": "ssh", "type": 28, "hostname": "127.0.0.1", "port": 22, "enabled": true},
+		{"uid": "80:00:00:00:01:00:40:C7", "secret": "WEBSECRET", "name": "web", "type": 7, "hostname": "127.0.0.1", "port": 80, "enabled": false}
+	]
+}`
+
+func Test_Restore_RestoreConfig(t *testing.T) {
+	var reply string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(reply))
+	}))
+	defer server.Close()
+
+	restoreClient := api.NewRestoreClient(server.URL, "token", apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+
+	reply = restoreConfigSample
+	config, errx := restoreClient.Restore(DEVICEID, MACHINEID)
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	if config.DeviceUID != "80:00:00:00:01:00:40:C5" || config.DeviceSecret != "DEVICESECRET" || string(config.Raw) != restoreConfigSample {
+		t.Errorf("unexpected config %+v", config)
+	}
+	if len(config.Services) != 2 || config.Services[0].Port != 22 || config.Services[0].Disabled || !config.Services[1].Disabled {
+		t.Errorf("unexpected services %v", config.Services)
+	}
+	if config.Names["80:00:00:00:01:00:40:C6"] != "ssh" || config.Names[config.DeviceUID] != "raspberrypi" {
+		t.Errorf("unexpected names %v", config.Names)
+	}
+
+	invalid := []string{
+		`{"device": {"uid": "80:00:00:00:01:00:40:C5"}}`,
+		`{"device": {"uid": "A", "secret": "S"}, "services": [{"uid": "B", "port": 22}]}`,
+		`{"device": {"uid": "A", "secret": "S"}, "services": [{"uid": "A", "secret": "S", "port": 22}]}`,
+	}
+	for _, body := range invalid {
+		reply = body
+		config, errx := restoreClient.Restore(DEVICEID, MACHINEID)
+		if errx == nil || errx.Code() != apiContracts.ErrAPI_RestoreClient_InvalidConfig.Code() {
+			t.Errorf("%s: expected an invalid config error, got %v", body, errx)
+		}
+
+		// what was parsed is kept to tell what is wrong
+		if string(config.Raw) != body || config.DeviceUID == "" {
+			t.Errorf("%s: expected the parsed config with the error, got %+v", body, config)
+		}
+	}
+}
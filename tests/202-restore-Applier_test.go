@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+type failingVerifyWriter struct {
+	api.AgentConfigWriter
+}
+
+func (thisRef failingVerifyWriter) Verify(data []byte) errorx.Error {
+	return errorx.New(apiContracts.ErrAPI_RestoreClient_InvalidConfig.Code(), "agent refused the config")
+}
+
+func Test_Restore_Applier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(restoreConfigSample))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "restore-applier")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	configPath := filepath.Join(dir, "config.json")
+	previous := []byte(`{"device": {"uid": "OLD", "secret": "OLDSECRET"}}`)
+	ioutil.WriteFile(configPath, previous, 0600)
+
+	restoreClient := api.NewRestoreClient(server.URL, "token", apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+	writer := api.NewFileAgentConfigWriter(configPath)
+
+	// 1. a dry run only reports the diff
+	result, errx := api.NewRestoreApplier(restoreClient, writer).Apply(DEVICEID, MACHINEID, true)
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	if !result.Changed || !strings.Contains(result.Diff, "- ") || !strings.Contains(result.Diff, "+ ") {
+		t.Errorf("unexpected dry run result %+v", result)
+	}
+	for _, secret := range []string{"OLDSECRET", "DEVICESECRET", "SSHSECRET", "WEBSECRET"} {
+		if strings.Contains(result.Diff, secret) {
+			t.Errorf("expected %s to be masked in the diff %s", secret, result.Diff)
+		}
+	}
+	if !strings.Contains(result.Diff, "- "+`{"device": {"uid": "OLD", "secret": "`+apiContracts.Redacted) {
+		t.Errorf("expected the masked previous secret in the diff %s", result.Diff)
+	}
+	if current, _ := ioutil.ReadFile(configPath); string(current) != string(previous) {
+		t.Error("expected a dry run to leave the config untouched")
+	}
+
+	// 2. a failed verification rolls back
+	_, errx = api.NewRestoreApplier(restoreClient, failingVerifyWriter{writer}).Apply(DEVICEID, MACHINEID, false)
+	if errx == nil || errx.Code() != apiContracts.ErrAPI_RestoreClient_CantVerifyConfig.Code() {
+		t.Errorf("expected a verification error, got %v", errx)
+	}
+	if current, _ := ioutil.ReadFile(configPath); string(current) != string(previous) {
+		t.Error("expected the config to be rolled back")
+	}
+
+	// 3. a real run backs up and writes
+	result, errx = api.NewRestoreApplier(restoreClient, writer).Apply(DEVICEID, MACHINEID, false)
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	backup, err := ioutil.ReadFile(result.BackupLocation)
+	if err != nil || string(backup) != string(previous) {
+		t.Errorf("expected the previous config to be backed up, got %v", err)
+	}
+
+	current, _ := ioutil.ReadFile(configPath)
+	if config, errx := apiContracts.ParseRestoreConfig(current); errx != nil || config.DeviceUID != "80:00:00:00:01:00:40:C5" {
+		t.Errorf("expected the restored config to be written, got %v", errx)
+	}
+
+	// 4. applying the same config again changes nothing
+	result, errx = api.NewRestoreApplier(restoreClient, writer).Apply(DEVICEID, MACHINEID, false)
+	if errx != nil || result.Changed || result.Diff != "" {
+		t.Errorf("expected no changes, got %+v, %v", result, errx)
+	}
+}
@@ -8,10 +8,13 @@ import (
 )
 
 func Test_Certificate_Generate(t *testing.T) {
-	client := api.NewClient(apiContracts.DEFAULT_API_URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+	server := newFakeAPI()
+	defer server.Close()
+
+	client := api.NewClient(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
 	authentication, _ := client.LoginWithPassword(USER, PASS)
 
-	certificateClient := api.NewCertificateClient(apiContracts.DEFAULT_API_CERTIFICATE_URL, authentication.Token, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+	certificateClient := api.NewCertificateClient(server.CertificateURL, authentication.Token, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
 
 	certificateRequest := apiContracts.CertificateRequest{
 		MachineID: "55eb0e08ddd14f8d8752a982e18bd4aa",
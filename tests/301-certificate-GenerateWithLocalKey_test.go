@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+func Test_Certificate_GenerateWithLocalKey(t *testing.T) {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, _ := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, caKey.Public(), caKey)
+	caCertificate, _ := x509.ParseCertificate(caDER)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request apiContracts.CertificateRequest
+		json.NewDecoder(r.Body).Decode(&request)
+
+		block, _ := pem.Decode([]byte(request.CSR))
+		if block == nil {
+			t.Error("expected a CSR in the request")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		if err != nil || csr.CheckSignature() != nil {
+			t.Errorf("invalid CSR: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      csr.Subject,
+			DNSNames:     csr.DNSNames,
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, _ := x509.CreateCertificate(rand.Reader, template, caCertificate, csr.PublicKey, caKey)
+
+		json.NewEncoder(w).Encode(apiContracts.CertificateResponse{
+			CN:          csr.Subject.CommonName,
+			Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		})
+	}))
+	defer server.Close()
+
+	certificateClient := api.NewCertificateClient(server.URL, "token", apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+
+	for _, keyType := range []apiContracts.CertificateKeyType{apiContracts.CertificateKeyECDSAP256, apiContracts.CertificateKeyRSA2048} {
+		certificateResponse, errx := certificateClient.GenerateWithLocalKey(apiContracts.CertificateRequest{
+			MachineID: MACHINEID,
+			ServiceID: "80:00:00:00:01:0C:2C:27",
+			Name:      "google_com-wwws",
+			IP:        "www.google.com",
+		}, keyType)
+		if errx != nil {
+			t.Error(errx)
+			t.FailNow()
+		}
+
+		if certificateResponse.CN != "google_com-wwws" || certificateResponse.Key == "" {
+			t.Errorf("unexpected response %+v", certificateResponse)
+		}
+	}
+}
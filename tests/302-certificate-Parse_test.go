@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+func newSelfSignedCertificateResponse(t *testing.T, commonName string, lifetime time.Duration) apiContracts.CertificateResponse {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(lifetime),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	keyDER, _ := x509.MarshalPKCS8PrivateKey(key)
+
+	return apiContracts.CertificateResponse{
+		CN:          commonName,
+		Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		Key:         string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})),
+	}
+}
+
+func Test_Certificate_Parse(t *testing.T) {
+	certificateResponse := newSelfSignedCertificateResponse(t, "service.example.com", time.Hour)
+
+	parsed, errx := certificateResponse.Parse()
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	if parsed.CommonName != "service.example.com" || len(parsed.DNSNames) != 1 || len(parsed.IPAddresses) != 1 {
+		t.Errorf("unexpected parsed certificate %+v", parsed)
+	}
+	if !parsed.IsValidAt(time.Now()) || parsed.Lifetime() <= time.Hour {
+		t.Errorf("unexpected validity %s - %s", parsed.NotBefore, parsed.NotAfter)
+	}
+
+	// 1. the tls.Config serves the certificate
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", parsed.TLSConfig())
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if conn.ConnectionState().PeerCertificates[0].Subject.CommonName != "service.example.com" {
+		t.Error("unexpected peer certificate")
+	}
+	conn.Close()
+
+	// 2. a key from another certificate is refused
+	certificateResponse.Key = newSelfSignedCertificateResponse(t, "other", time.Hour).Key
+	if _, errx := certificateResponse.Parse(); errx != apiContracts.ErrAPI_CertClient_KeyMismatch {
+		t.Errorf("expected %v, got %v", apiContracts.ErrAPI_CertClient_KeyMismatch, errx)
+	}
+}
@@ -0,0 +1,204 @@
+package tests
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+type fakeCertificateClient struct {
+	t        *testing.T
+	lifetime time.Duration
+
+	mutex sync.Mutex
+	calls int
+}
+
+func (thisRef *fakeCertificateClient) Generate(request apiContracts.CertificateRequest) (*apiContracts.CertificateResponse, errorx.Error) {
+	thisRef.mutex.Lock()
+	thisRef.calls++
+	thisRef.mutex.Unlock()
+
+	response := newSelfSignedCertificateResponse(thisRef.t, request.Name, thisRef.lifetime)
+	return &response, nil
+}
+
+func (thisRef *fakeCertificateClient) GenerateWithLocalKey(request apiContracts.CertificateRequest, keyType apiContracts.CertificateKeyType) (*apiContracts.CertificateResponse, errorx.Error) {
+	return thisRef.Generate(request)
+}
+
+func (thisRef *fakeCertificateClient) Calls() int {
+	thisRef.mutex.Lock()
+	defer thisRef.mutex.Unlock()
+
+	return thisRef.calls
+}
+
+func Test_Certificate_Renewer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certificate-renewer")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	options := apiContracts.CertificateRenewerOptions{
+		Request:         apiContracts.CertificateRequest{Name: "service.example.com"},
+		CertificatePath: filepath.Join(dir, "cert.pem"),
+		KeyPath:         filepath.Join(dir, "key.pem"),
+	}
+
+	// 1. a stored certificate that is past its renewal time is renewed on start
+	stale := newSelfSignedCertificateResponse(t, "stale", time.Second)
+	ioutil.WriteFile(options.CertificatePath, []byte(stale.Certificate), 0644)
+	ioutil.WriteFile(options.KeyPath, []byte(stale.Key), 0600)
+
+	certificateClient := &fakeCertificateClient{t: t, lifetime: time.Hour}
+	renewer := api.NewCertificateRenewer(certificateClient, options)
+	if errx := renewer.Start(); errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	defer renewer.Stop()
+
+	certificate, err := renewer.GetCertificate(nil)
+	if err != nil || certificate.Leaf.Subject.CommonName != "service.example.com" {
+		t.Errorf("expected the renewed certificate to be served, got %v", err)
+		t.FailNow()
+	}
+
+	info, err := os.Stat(options.KeyPath)
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected the stored key to be private, got %v", info.Mode())
+	}
+
+	stored, _ := ioutil.ReadFile(options.CertificatePath)
+	if string(stored) == stale.Certificate {
+		t.Error("expected the renewed certificate to be stored")
+	}
+
+	// 2. a fresh stored certificate is reused without calling the API
+	renewer.Stop()
+	calls := certificateClient.Calls()
+	renewer = api.NewCertificateRenewer(certificateClient, options)
+	if errx := renewer.Start(); errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	if certificateClient.Calls() != calls {
+		t.Error("expected the stored certificate to be reused")
+	}
+	renewer.Stop()
+
+	// 3. short lived certificates are renewed in the background
+	shortLived := &fakeCertificateClient{t: t, lifetime: time.Second}
+	options.CertificatePath = filepath.Join(dir, "short-lived", "cert.pem")
+	options.KeyPath = filepath.Join(dir, "short-lived", "key.pem")
+	options.RenewAtFraction = 0.99
+	renewed := make(chan struct{}, 10)
+	options.RetryInterval = 100 * time.Millisecond
+	options.OnRenewed = func(apiContracts.ParsedCertificate) {
+		select {
+		case renewed <- struct{}{}:
+		default:
+		}
+	}
+
+	renewer = api.NewCertificateRenewer(shortLived, options)
+	if errx := renewer.Start(); errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	defer renewer.Stop()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-renewed:
+		case <-time.After(5 * time.Second):
+			t.Error("expected the certificate to be renewed in the background")
+			t.FailNow()
+		}
+	}
+}
+
+type failingCertificateClient struct {
+	fakeCertificateClient
+}
+
+func (thisRef *failingCertificateClient) Generate(request apiContracts.CertificateRequest) (*apiContracts.CertificateResponse, errorx.Error) {
+	thisRef.mutex.Lock()
+	thisRef.calls++
+	thisRef.mutex.Unlock()
+
+	return nil, apiContracts.ErrAPI_CertClient_CantSendRequest
+}
+
+func (thisRef *failingCertificateClient) GenerateWithLocalKey(request apiContracts.CertificateRequest, keyType apiContracts.CertificateKeyType) (*apiContracts.CertificateResponse, errorx.Error) {
+	return thisRef.Generate(request)
+}
+
+func Test_Certificate_Renewer_Retries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certificate-renewer")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	errsMutex := sync.Mutex{}
+	errs := []errorx.Error{}
+	options := apiContracts.CertificateRenewerOptions{
+		Request:         apiContracts.CertificateRequest{Name: "service.example.com"},
+		CertificatePath: filepath.Join(dir, "cert.pem"),
+		KeyPath:         filepath.Join(dir, "key.pem"),
+		RetryInterval:   100 * time.Millisecond,
+		OnError: func(errx errorx.Error) {
+			errsMutex.Lock()
+			defer errsMutex.Unlock()
+			errs = append(errs, errx)
+		},
+	}
+
+	// a due certificate that is still valid for a second
+	due := newSelfSignedCertificateResponse(t, "due", time.Second)
+	ioutil.WriteFile(options.CertificatePath, []byte(due.Certificate), 0644)
+	ioutil.WriteFile(options.KeyPath, []byte(due.Key), 0600)
+
+	certificateClient := &failingCertificateClient{}
+	renewer := api.NewCertificateRenewer(certificateClient, options)
+	if errx := renewer.Start(); errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	defer renewer.Stop()
+
+	// 1. the failed renewals are retried every RetryInterval, not every other one
+	time.Sleep(550 * time.Millisecond)
+	if calls := certificateClient.Calls(); calls < 4 {
+		t.Errorf("expected a retry every 100ms, got %d calls in 550ms", calls)
+	}
+
+	// 2. once expired it is no longer served, and reported
+	time.Sleep(1500 * time.Millisecond)
+	if _, err := renewer.GetCertificate(nil); err != apiContracts.ErrAPI_CertClient_Expired {
+		t.Errorf("expected %v, got %v", apiContracts.ErrAPI_CertClient_Expired, err)
+	}
+
+	errsMutex.Lock()
+	defer errsMutex.Unlock()
+
+	reported := false
+	for _, errx := range errs {
+		reported = reported || errx == apiContracts.ErrAPI_CertClient_Expired
+	}
+	if !reported {
+		t.Errorf("expected the expiry to be reported, got %v", errs)
+	}
+}
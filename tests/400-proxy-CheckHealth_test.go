@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+func Test_Proxy_CheckHealth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	connection := apiContracts.ProxyConnectionInfo{
+		ProxyServer: host,
+		ProxyPort:   port,
+	}
+
+	proxy := api.NewProxy(nil)
+
+	for _, mode := range []apiContracts.ProxyHealthCheckMode{apiContracts.ProxyHealthCheckTCP, apiContracts.ProxyHealthCheckHTTP} {
+		health, errx := proxy.CheckHealth(connection, apiContracts.ProxyHealthCheckRequest{Mode: mode})
+		if errx != nil {
+			t.Error(errx)
+			t.FailNow()
+		}
+
+		if !health.Reachable {
+			t.Errorf("mode %d: expected endpoint to be reachable: %s", mode, health.Reason)
+			t.FailNow()
+		}
+	}
+
+	server.Close()
+
+	health, errx := proxy.CheckHealth(connection, apiContracts.ProxyHealthCheckRequest{Mode: apiContracts.ProxyHealthCheckTCP})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	if health.Reachable {
+		t.Error("expected closed endpoint to be unreachable")
+		t.FailNow()
+	}
+
+	if _, errx := proxy.CheckHealth(apiContracts.ProxyConnectionInfo{}, apiContracts.ProxyHealthCheckRequest{}); errx != apiContracts.ErrAPI_ProxyHealth_NoEndpoint {
+		t.Errorf("expected %v, got %v", apiContracts.ErrAPI_ProxyHealth_NoEndpoint, errx)
+	}
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+func Test_AutoReg_AutoRegisterIfNeeded(t *testing.T) {
+	const registrationKey = "REGKEY"
+	const resetHardwareID = "reset-hardware-id"
+
+	templateRequests := 0
+	registerRequests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/bulk/registration/device/information/"):
+			w.Write([]byte(`{"status":"true"}`))
+
+		case strings.HasPrefix(r.URL.Path, "/bulk/registration/device/friendly/configuration/"):
+			templateRequests++
+			if templateRequests == 1 {
+				w.Write([]byte(`{"status":"reset","HardwareId":"` + resetHardwareID + `"}`))
+				return
+			}
+			if !strings.Contains(r.URL.Path, resetHardwareID) {
+				t.Errorf("expected the reset hardware ID to be used, got %s", r.URL.Path)
+			}
+			w.Write([]byte(`{"status":"true","projects":"T1,T2"}`))
+
+		case strings.HasPrefix(r.URL.Path, "/bulk/registration/configuration/"):
+			w.Write([]byte(`{"status":"true","content_ip":"127.0.0.1","content_port":"22","content_type":"28","enabled":"1","project_id":"P1"}`))
+
+		case r.URL.Path == "/bulk/registration/register":
+			registerRequests++
+			if registerRequests == 1 {
+				w.Write([]byte(`{"status":"pending"}`))
+				return
+			}
+			w.Write([]byte(`{"status":"true","uid":"80:00:00:00:00:00:00:01","secret":"SECRET"}`))
+
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+	autoRegistration := api.NewAutoRegistration(client)
+
+	services, errx := autoRegistration.AutoRegisterIfNeeded(apiContracts.AutoRegistrationRequest{
+		RegistrationKey: registrationKey,
+		HardwareID:      "original-hardware-id",
+		InitialBackoff:  time.Millisecond,
+	})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	if len(services) != 2 {
+		t.Errorf("expected 2 services, got %d", len(services))
+		t.FailNow()
+	}
+
+	for _, service := range services {
+		if service.HardwareID != resetHardwareID || service.UID == "" || service.Secret == "" || service.Port != 22 {
+			t.Errorf("unexpected service %s", service)
+		}
+	}
+
+	if _, errx := autoRegistration.AutoRegisterIfNeeded(apiContracts.AutoRegistrationRequest{}); errx != apiContracts.ErrAutoreg_BICEmpty {
+		t.Errorf("expected %v, got %v", apiContracts.ErrAutoreg_BICEmpty, errx)
+	}
+}
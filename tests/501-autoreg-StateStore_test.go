@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+func Test_AutoReg_StateStore_Resume(t *testing.T) {
+	registered := map[string]int{}
+	failT2 := true
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/bulk/registration/device/information/"):
+			w.Write([]byte(`{"status":"true"}`))
+
+		case strings.HasPrefix(r.URL.Path, "/bulk/registration/device/friendly/configuration/"):
+			w.Write([]byte(`{"status":"true","projects":"T1,T2"}`))
+
+		case strings.HasPrefix(r.URL.Path, "/bulk/registration/configuration/"):
+			w.Write([]byte(`{"status":"true","content_ip":"127.0.0.1","content_port":"22","content_type":"28","enabled":"1"}`))
+
+		case r.URL.Path == "/bulk/registration/register":
+			var body struct {
+				ProjectID string `json:"project_id"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+
+			if body.ProjectID == "T2" && failT2 {
+				w.Write([]byte(`{"status":"false","reason":"device rebooted"}`))
+				return
+			}
+
+			registered[body.ProjectID]++
+			w.Write([]byte(`{"status":"true","uid":"UID-` + body.ProjectID + `","secret":"SECRET"}`))
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "autoreg-state")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	stateStore := api.NewFileAutoRegistrationStateStore(filepath.Join(dir, "state.json"))
+	client := api.NewClient(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+	request := apiContracts.AutoRegistrationRequest{
+		RegistrationKey: "REGKEY",
+		HardwareID:      "hardware-id",
+		InitialBackoff:  time.Millisecond,
+	}
+
+	// 1. first run is interrupted after T1
+	if _, errx := api.NewAutoRegistrationWithStateStore(client, stateStore).AutoRegisterIfNeeded(request); errx == nil {
+		t.Error("expected the first run to fail")
+		t.FailNow()
+	}
+
+	state, found, errx := stateStore.Load()
+	if errx != nil || !found {
+		t.Errorf("expected a saved state, got %v", errx)
+		t.FailNow()
+	}
+	if _, ok := state.Registered["T1"]; !ok || len(state.Pending) != 1 || state.Pending[0] != "T2" {
+		t.Errorf("unexpected state %+v", state)
+		t.FailNow()
+	}
+
+	// 2. second run resumes with T2 only
+	failT2 = false
+	services, errx := api.NewAutoRegistrationWithStateStore(client, stateStore).AutoRegisterIfNeeded(request)
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	if len(services) != 2 {
+		t.Errorf("expected 2 services, got %d", len(services))
+		t.FailNow()
+	}
+	if registered["T1"] != 1 || registered["T2"] != 1 {
+		t.Errorf("expected every template to be registered exactly once, got %v", registered)
+	}
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	api "github.com/remoteit/sdk-go"
+)
+
+func Test_AutoReg_LinuxHardwareIdentityCollector(t *testing.T) {
+	root, err := ioutil.TempDir("", "hardware-identity")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(root)
+
+	files := map[string]string{
+		"etc/machine-id":                "55eb0e08ddd14f8d8752a982e18bd4aa\n",
+		"etc/os-release":                "NAME=\"Raspbian GNU/Linux\"\nPRETTY_NAME=\"Raspbian GNU/Linux 10 (buster)\"\n",
+		"proc/cpuinfo":                  "processor\t: 0\nmodel name\t: ARMv7 Processor rev 4 (v7l)\n\nHardware\t: BCM2835\nSerial\t\t: 00000000a3e2c4b1\n",
+		"sys/class/net/lo/address":      "00:00:00:00:00:00\n",
+		"sys/class/net/docker0/address": "02:42:ac:11:00:01\n",
+		"sys/class/net/eth0/address":    "B8:27:EB:12:34:56\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		ioutil.WriteFile(path, []byte(content), 0644)
+	}
+	os.MkdirAll(filepath.Join(root, "sys/class/net/eth0/device"), 0755)
+
+	collector := api.NewLinuxHardwareIdentityCollector(root)
+
+	info, errx := collector.Collect("4.9.1")
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	if info.MACAddress != "b8:27:eb:12:34:56" {
+		t.Errorf("unexpected MAC address %s", info.MACAddress)
+	}
+	if info.CPUID != "00000000a3e2c4b1" {
+		t.Errorf("unexpected CPU ID %s", info.CPUID)
+	}
+	if info.PlatformOSName != "Raspbian GNU/Linux 10 (buster)" {
+		t.Errorf("unexpected OS name %s", info.PlatformOSName)
+	}
+	if info.Version != "4.9.1" || info.HardwareID == "" {
+		t.Errorf("unexpected device info %+v", info)
+	}
+
+	again, _ := collector.Collect("4.9.1")
+	if again.HardwareID != info.HardwareID {
+		t.Error("expected the hardware ID to be stable")
+	}
+
+	// a replaced network card keeps the hardware ID
+	os.RemoveAll(filepath.Join(root, "sys/class/net/eth0"))
+	os.MkdirAll(filepath.Join(root, "sys/class/net/enp1s0/device"), 0755)
+	ioutil.WriteFile(filepath.Join(root, "sys/class/net/enp1s0/address"), []byte("3c:7c:3f:aa:bb:cc\n"), 0644)
+
+	replaced, errx := collector.Collect("4.9.1")
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	if replaced.MACAddress != "3c:7c:3f:aa:bb:cc" {
+		t.Errorf("unexpected MAC address %s", replaced.MACAddress)
+	}
+	if replaced.HardwareID != info.HardwareID {
+		t.Error("expected the hardware ID to survive a network card change")
+	}
+
+	if _, errx := api.NewLinuxHardwareIdentityCollector(filepath.Join(root, "missing")).Collect(""); errx == nil {
+		t.Error("expected an error without machine ID and DMI product UUID")
+	}
+}
+
+func Test_AutoReg_LinuxHardwareIdentityCollector_DMI(t *testing.T) {
+	root, err := ioutil.TempDir("", "hardware-identity")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(root)
+
+	files := map[string]string{
+		"sys/class/dmi/id/product_uuid":   "Not Specified\n",
+		"sys/class/dmi/id/product_serial": "PF2ABCDE\n",
+		"sys/class/net/eth0/address":      "b8:27:eb:12:34:56\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		ioutil.WriteFile(path, []byte(content), 0644)
+	}
+
+	collector := api.NewLinuxHardwareIdentityCollector(root)
+
+	info, errx := collector.Collect("4.9.1")
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	if info.MachineID != "" || info.HardwareID == "" {
+		t.Errorf("unexpected device info %+v", info)
+	}
+
+	ioutil.WriteFile(filepath.Join(root, "sys/class/dmi/id/product_uuid"), []byte("4C4C4544-0042-3510-8052-B4C04F565331\n"), 0644)
+	withUUID, _ := collector.Collect("4.9.1")
+	if withUUID.HardwareID == info.HardwareID {
+		t.Error("expected the product UUID to take precedence over the product serial")
+	}
+
+	os.RemoveAll(filepath.Join(root, "sys/class/dmi"))
+	if _, errx := collector.Collect("4.9.1"); errx == nil {
+		t.Error("expected an error with only a MAC address")
+	}
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+func Test_AutoReg_Provisioning(t *testing.T) {
+	bundle := []byte("provisioning bundle contents")
+	hash := sha256.Sum256(bundle)
+	checksum := hex.EncodeToString(hash[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/device/component/version":
+			w.Write([]byte(`{"status":"true"}`))
+		case strings.HasPrefix(r.URL.Path, "/device/enablement/"):
+			w.Write([]byte(`{"status":"true","project_id":"P1","enabled":"1"}`))
+		case strings.HasPrefix(r.URL.Path, "/project/provisioning/download/"):
+			w.Write(bundle)
+		case strings.HasPrefix(r.URL.Path, "/project/provisioning/"):
+			w.Write([]byte(`{"status":"true","project_id":"P1","version":"3","filename":"bundle.tar.gz","size":"28","sha256":"` + checksum + `"}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+	autoRegistration := api.NewAutoRegistration(client)
+
+	errx := autoRegistration.ReportComponentVersions("80:00:00:00:00:00:00:01", []apiContracts.ComponentVersion{{Name: "connectd", Version: "4.9"}})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	enablement, errx := autoRegistration.GetProjectEnablement("REGKEY", "hardware-id")
+	if errx != nil || !enablement.Enabled || enablement.ProjectID != "P1" {
+		t.Errorf("unexpected enablement %+v, %v", enablement, errx)
+		t.FailNow()
+	}
+
+	provisioning, errx := autoRegistration.GetProvisioning("REGKEY", "hardware-id")
+	if errx != nil || provisioning.Size != int64(len(bundle)) || provisioning.Checksum != checksum {
+		t.Errorf("unexpected provisioning %+v, %v", provisioning, errx)
+		t.FailNow()
+	}
+
+	var buffer bytes.Buffer
+	written, errx := autoRegistration.DownloadProvisioning("REGKEY", "hardware-id", provisioning.Checksum, &buffer)
+	if errx != nil || written != int64(len(bundle)) || !bytes.Equal(buffer.Bytes(), bundle) {
+		t.Errorf("unexpected download of %d bytes, %v", written, errx)
+		t.FailNow()
+	}
+
+	if _, errx := autoRegistration.DownloadProvisioning("REGKEY", "hardware-id", strings.Repeat("0", 64), &bytes.Buffer{}); errx != apiContracts.ErrAutoreg_ChecksumMismatch {
+		t.Errorf("expected %v, got %v", apiContracts.ErrAutoreg_ChecksumMismatch, errx)
+	}
+}
+
+func Test_AutoReg_Provisioning_SlowDownload(t *testing.T) {
+	chunk := []byte("provisioning bundle chunk\n")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 5 chunks 40ms apart take longer than the 100ms timeout, but never stall that long
+		for i := 0; i < 5; i++ {
+			w.Write(chunk)
+			w.(http.Flusher).Flush()
+			time.Sleep(40 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, APIKEY, 100*time.Millisecond, apiContracts.DEFAULT_API_USER_AGENT)
+
+	var buffer bytes.Buffer
+	written, errx := api.NewAutoRegistration(client).DownloadProvisioning("REGKEY", "hardware-id", "", &buffer)
+	if errx != nil || written != int64(5*len(chunk)) {
+		t.Errorf("unexpected download of %d bytes, %v", written, errx)
+	}
+
+	// a stall longer than the timeout fails
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(chunk)
+		w.(http.Flusher).Flush()
+		time.Sleep(300 * time.Millisecond)
+		w.Write(chunk)
+	}))
+	defer stalled.Close()
+
+	client = api.NewClient(stalled.URL, APIKEY, 100*time.Millisecond, apiContracts.DEFAULT_API_USER_AGENT)
+	if _, errx := api.NewAutoRegistration(client).DownloadProvisioning("REGKEY", "hardware-id", "", &bytes.Buffer{}); errx == nil {
+		t.Error("expected the stalled download to fail")
+	}
+}
@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+func Test_AutoReg_GetServiceConfigFromTemplateID(t *testing.T) {
+	var reply string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(reply))
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+	autoRegistration := api.NewAutoRegistration(client)
+
+	// 1. valid configs
+	reply = `{"status":"true","content_ip":"127.0.0.1","content_port":"22","content_type":"28","enabled":"1","project_id":"P1","timestamp":"1610752800"}`
+	config, errx := autoRegistration.GetServiceConfigFromTemplateID("T1", "hardware-id")
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	if config.Port != 22 || config.Type != 28 || config.Protocol != apiContracts.TCP || config.Disabled || config.ProjectID != "P1" || config.Timestamp != "1610752800" {
+		t.Errorf("unexpected config %+v", config)
+	}
+
+	reply = `{"status":"true","content_ip":"127.0.0.1","content_port":"22, 80,443","content_type":"35","enabled":"1"}`
+	config, errx = autoRegistration.GetServiceConfigFromTemplateID("T1", "hardware-id")
+	if errx != nil || len(config.Ports) != 3 || config.Port != 22 || !config.IsMultiPort() {
+		t.Errorf("unexpected multi-port config %+v, %v", config, errx)
+	}
+
+	reply = `{"status":"true","content_ip":"127.0.0.1","content_port":"53","content_type":"32769","enabled":"1"}`
+	config, errx = autoRegistration.GetServiceConfigFromTemplateID("T1", "hardware-id")
+	if errx != nil || config.Protocol != apiContracts.UDP {
+		t.Errorf("unexpected UDP config %+v, %v", config, errx)
+	}
+
+	// 2. invalid configs
+	cases := map[string]interface{}{
+		`{"status":"true","content_port":"ssh","content_type":"28"}`:   apiContracts.ErrAutoreg_CantConvertPort,
+		`{"status":"true","content_port":"70000","content_type":"28"}`: apiContracts.ErrAutoreg_CantConvertPort,
+		`{"status":"true","content_port":"22,80","content_type":"28"}`: apiContracts.ErrAutoreg_CantConvertPort,
+		`{"status":"true","content_port":"22","content_type":"tcp"}`:   apiContracts.ErrAutoreg_CantConvertType,
+		`{"status":"false","reason":"no matching bulk project"}`:       apiContracts.ErrAutoreg_NoMatchingRegInfo,
+		`not json`: apiContracts.ErrAutoreg_CantReadResponse,
+	}
+	for body, expected := range cases {
+		reply = body
+		if _, errx := autoRegistration.GetServiceConfigFromTemplateID("T1", "hardware-id"); errx != expected {
+			t.Errorf("%s: expected %v, got %v", body, expected, errx)
+		}
+	}
+}
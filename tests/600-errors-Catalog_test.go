@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+func Test_Errors_CatalogCodesAreUnique(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "../contracts/errors.go", nil, 0)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	// every error declared in errors.go must go through the catalog with its own code
+	seen := map[int]string{}
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.ValueSpec)
+		if !ok || len(spec.Values) != 1 {
+			return true
+		}
+
+		name := spec.Names[0].Name
+		call, ok := spec.Values[0].(*ast.CallExpr)
+		if !ok {
+			if _, isString := spec.Values[0].(*ast.BasicLit); !isString || name != "MFA_IS_ENABLED" {
+				t.Errorf("%s is not registered in the error catalog", name)
+			}
+			return true
+		}
+
+		function, ok := call.Fun.(*ast.Ident)
+		if !ok || (function.Name != "newError" && function.Name != "newErrorCode") || len(call.Args) < 2 {
+			t.Errorf("%s is not registered in the error catalog", name)
+			return true
+		}
+
+		literal, ok := call.Args[1].(*ast.BasicLit)
+		if !ok {
+			t.Errorf("%s does not use a literal error code", name)
+			return true
+		}
+
+		code, _ := strconv.Atoi(literal.Value)
+		if other, duplicate := seen[code]; duplicate {
+			t.Errorf("error code %d is used by both %s and %s", code, other, name)
+		}
+		seen[code] = name
+
+		return true
+	})
+
+	if len(seen) != len(apiContracts.ErrorCatalog()) {
+		t.Errorf("expected %d catalog entries, got %d", len(seen), len(apiContracts.ErrorCatalog()))
+	}
+}
+
+func Test_Errors_ErrorByCode(t *testing.T) {
+	errx, ok := apiContracts.ErrorByCode(apiContracts.ErrAPI_Auth_PasswordInvalid.Code())
+	if !ok || errx != apiContracts.ErrAPI_Auth_PasswordInvalid {
+		t.Errorf("expected %v, got %v", apiContracts.ErrAPI_Auth_PasswordInvalid, errx)
+	}
+
+	category, ok := apiContracts.ErrorCategoryByCode(apiContracts.ErrAPI_ProxyCreate_Generic)
+	if !ok || category != apiContracts.ErrorCategoryProxy {
+		t.Errorf("expected %s, got %s", apiContracts.ErrorCategoryProxy, category)
+	}
+
+	if _, ok := apiContracts.ErrorByCode(99999); ok {
+		t.Error("expected no error for an unknown code")
+	}
+
+	for _, errx := range apiContracts.ErrorsByCategory(apiContracts.ErrorCategoryCertificate) {
+		if errx.Code() < 7000 || errx.Code() >= 8000 {
+			t.Errorf("unexpected certificate error %v", errx)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+func Test_Errors_IsAndAs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user/login":
+			w.Write([]byte(`{"status":"false","reason":"[0102] The username or password are invalid"}`))
+		case "/user/login/authhash":
+			w.Write([]byte(`{"status":"false","reason":"account locked"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+
+	// 1. sentinels match, wrapped or not
+	_, errx := client.LoginWithPassword(USER, PASS)
+	wrapped := fmt.Errorf("login failed: %w", errx)
+	if !errors.Is(errx, apiContracts.ErrAPI_Auth_PasswordInvalid) || !errors.Is(wrapped, apiContracts.ErrAPI_Auth_PasswordInvalid) {
+		t.Errorf("expected %v to match %v", wrapped, apiContracts.ErrAPI_Auth_PasswordInvalid)
+	}
+	if errors.Is(wrapped, apiContracts.ErrAPI_Auth_NoSuchUser) {
+		t.Errorf("expected %v not to match %v", wrapped, apiContracts.ErrAPI_Auth_NoSuchUser)
+	}
+
+	// 2. generic errors match by code and carry the reason
+	_, errx = client.LoginWithAuthHashIgnoreCache(USER, "authhash")
+	wrapped = fmt.Errorf("login failed: %w", errx)
+	if !errors.Is(wrapped, apiContracts.ErrorCode(apiContracts.ErrAPI_Auth_Generic)) {
+		t.Errorf("expected %v to match code %d", wrapped, apiContracts.ErrAPI_Auth_Generic)
+	}
+
+	generic, _ := apiContracts.ErrorByCode(apiContracts.ErrAPI_Auth_Generic)
+	if !errors.Is(wrapped, generic) {
+		t.Errorf("expected %v to match the catalog entry %v", wrapped, generic)
+	}
+
+	var apiError *apiContracts.Error
+	if !errors.As(wrapped, &apiError) || apiError.Reason != "account locked" {
+		t.Errorf("expected the reason to be exposed, got %+v", apiError)
+	}
+
+	// 3. errors from other errorx sources match by code too
+	if !errors.Is(apiContracts.ErrAPI_Device_Unknown, errorx.New(apiContracts.ErrAPI_Device_Unknown.Code(), "")) {
+		t.Error("expected errorx errors with the same code to match")
+	}
+
+	// 4. underlying errors stay reachable
+	cause := errors.New("disk full")
+	if !errors.Is(apiContracts.NewErrorFromErr(apiContracts.ErrAutoreg_CantSaveState.Code(), cause), cause) {
+		t.Error("expected the cause to be unwrapped")
+	}
+}
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+func Test_Errors_ParseAPIError(t *testing.T) {
+	tests := []struct {
+		reason string
+		code   string
+		text   string
+	}{
+		{"[0806] device not found", "0806", "device not found"},
+		{"  Invalid request [0861]: service not found ", "0861", "Invalid request : service not found"},
+		{"missing api token", "", "missing api token"},
+		{"", "", ""},
+	}
+
+	for _, test := range tests {
+		apiError := apiContracts.ParseAPIError(test.reason)
+		if apiError.Code != test.code || apiError.Reason != test.text || apiError.Raw != test.reason {
+			t.Errorf("%q: expected code %q and reason %q, got %+v", test.reason, test.code, test.text, apiError)
+		}
+	}
+
+	if _, ok := apiContracts.ParseAPIErrorFromResponse([]byte(`{"status":"true"}`)); ok {
+		t.Error("expected a successful response not to be an API error")
+	}
+
+	apiError, ok := apiContracts.ParseAPIErrorFromResponse([]byte(`{"status":"false","reason":"[0807] duplicate name"}`))
+	if !ok || apiError.Code != apiContracts.API_ERROR_CODE_DUPLICATE_NAME {
+		t.Errorf("expected code %s, got %+v", apiContracts.API_ERROR_CODE_DUPLICATE_NAME, apiError)
+	}
+}
+
+func Test_Errors_APIErrorMapping(t *testing.T) {
+	reasons := map[string]string{
+		"/developer/device/delete/registered/mapped":  "[0861] service not found for UID",
+		"/developer/device/delete/registered/generic": "[0999] something new",
+		"/developer/device/delete/registered/empty":   "",
+		"/device/connect": "[0861] service not found for UID",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"false","reason":"` + reasons[r.URL.Path] + `"}`))
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+	device := api.NewDevice(client)
+
+	// 1. known codes map to their SDK error
+	if errx := device.Unregister("mapped"); !errors.Is(errx, apiContracts.ErrAPI_Device_NoServiceFound) {
+		t.Errorf("expected %v, got %v", apiContracts.ErrAPI_Device_NoServiceFound, errx)
+	}
+
+	// 2. unknown codes become the generic error and keep the API code
+	errx := device.Unregister("generic")
+	var apiError *apiContracts.Error
+	if !errors.As(errx, &apiError) || apiError.Code() != apiContracts.ErrAPI_Device_Generic || apiError.APICode != "0999" {
+		t.Errorf("expected a generic error with API code 0999, got %+v", errx)
+	}
+
+	// 3. no reason at all
+	if errx := device.Unregister("empty"); !errors.Is(errx, apiContracts.ErrAPI_Device_Unknown) {
+		t.Errorf("expected %v, got %v", apiContracts.ErrAPI_Device_Unknown, errx)
+	}
+
+	// 4. the same code maps per call
+	_, errx = api.NewProxy(client).Create(apiContracts.CreateProxyRequest{DeviceAddress: "80:00:00:00:01:00:40:C5"})
+	if !errors.Is(errx, apiContracts.ErrAPI_ProxyCreate_NoServiceFound) {
+		t.Errorf("expected %v, got %v", apiContracts.ErrAPI_ProxyCreate_NoServiceFound, errx)
+	}
+}
@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	api "github.com/remoteit/sdk-go"
+	"github.com/remoteit/sdk-go/apitest"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+func Test_APITest_Devices(t *testing.T) {
+	server := newFakeAPI()
+	defer server.Close()
+
+	client := api.NewClient(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+	authentication, _ := client.LoginWithPassword(USER, PASS)
+	if _, errx := client.LoginWithAuthHashIgnoreCache(USER, authentication.AuthHash); errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	// 1. register
+	service := api.NewService(client)
+	uid, errx := service.GenerateUID("key", "secret")
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	serviceType := apiContracts.GetServiceType(apiContracts.DefaultServiceType, 7, 0, 0)
+	secret, errx := service.Register("web", uid, MACHINEID, serviceType, 7)
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	if device, ok := server.Device(uid); !ok || device.Secret != secret || device.Owner != USER {
+		t.Errorf("expected %s to be registered with secret %s, got %+v", uid, secret, device)
+	}
+
+	_, errx = service.Register("ssh", "80:00:00:00:01:00:00:99", MACHINEID, serviceType, 7)
+	var apiError *apiContracts.Error
+	if !errors.As(errx, &apiError) || apiError.APICode != apiContracts.API_ERROR_CODE_DUPLICATE_NAME {
+		t.Errorf("expected a duplicate name error, got %v", errx)
+	}
+
+	// 2. list, with an injected failure first
+	server.Fail("/device/list/all", apitest.Failure{Reason: "[0999] maintenance", Times: 1})
+
+	device := api.NewDevice(client)
+	if _, errx := device.ListAll(); !errors.Is(errx, apiContracts.ErrorCode(apiContracts.ErrAPI_DeviceList_Generic)) {
+		t.Errorf("expected the injected failure, got %v", errx)
+	}
+
+	list, errx := device.ListAll()
+	if errx != nil || len(list.Devices) != 3 {
+		t.Errorf("expected 3 devices, got %v %v", list.Devices, errx)
+	}
+
+	// 3. connect, check and stop
+	proxy := api.NewProxy(client)
+	created, errx := proxy.Create(apiContracts.CreateProxyRequest{DeviceAddress: uid})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	health, errx := proxy.CheckHealth(created.Connection, apiContracts.ProxyHealthCheckRequest{Mode: apiContracts.ProxyHealthCheckTCP})
+	if errx != nil || !health.Reachable {
+		t.Errorf("expected the connection to be reachable, got %+v %v", health, errx)
+	}
+
+	if _, errx := proxy.Delete(apiContracts.DeleteProxyRequest{DeviceAddress: uid, ConnectionID: created.Connection.ConnectionID}); errx != nil || len(server.Connections()) != 0 {
+		t.Errorf("expected the connection to be closed, got %v %v", server.Connections(), errx)
+	}
+
+	// 4. transfer, then it is gone for this account
+	server.AddAccount(apitest.Account{Username: "other", Password: "other"})
+	if errx := device.Transfer(uid, "other"); errx != nil || len(server.Devices("other")) != 1 {
+		t.Errorf("expected %s to be transferred, got %v", uid, errx)
+	}
+	if errx := device.Unregister(uid); !errors.Is(errx, apiContracts.ErrAPI_Device_NoServiceFound) {
+		t.Errorf("expected %v, got %v", apiContracts.ErrAPI_Device_NoServiceFound, errx)
+	}
+}
+
+func Test_APITest_OverlappingFailures(t *testing.T) {
+	server := newFakeAPI()
+	defer server.Close()
+
+	client := api.NewClient(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+	authentication, _ := client.LoginWithPassword(USER, PASS)
+	if _, errx := client.LoginWithAuthHashIgnoreCache(USER, authentication.AuthHash); errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	device := api.NewDevice(client)
+
+	// the longest prefix wins, whatever order the failures are kept in
+	for i := 0; i < 20; i++ {
+		server.Fail("/device", apitest.Failure{Reason: "[0999] maintenance"})
+		server.Fail("/device/list", apitest.Failure{Reason: "[0998] list maintenance", Times: 1})
+
+		var apiError *apiContracts.Error
+		if _, errx := device.ListAll(); !errors.As(errx, &apiError) || apiError.APICode != "0998" {
+			t.Fatalf("expected the /device/list failure, got %v", errx)
+		}
+		if _, errx := device.ListAll(); !errors.As(errx, &apiError) || apiError.APICode != "0999" {
+			t.Fatalf("expected the /device failure once /device/list is used up, got %v", errx)
+		}
+
+		server.ClearFailures()
+	}
+}
+
+func Test_APITest_AutoRegistration(t *testing.T) {
+	server := newFakeAPI()
+	defer server.Close()
+
+	server.AddBulkProject(apitest.BulkProject{
+		RegistrationKey: "REGKEY",
+		Owner:           USER,
+		Templates:       []apitest.BulkTemplate{{ID: "T1", Type: 28, Hostname: "127.0.0.1", Port: "22"}},
+		PendingPolls:    1,
+	})
+
+	client := api.NewClient(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT)
+	request := apiContracts.AutoRegistrationRequest{
+		RegistrationKey: "REGKEY",
+		HardwareID:      "hardware-id",
+		InitialBackoff:  time.Millisecond,
+	}
+
+	services, errx := api.NewAutoRegistration(client).AutoRegisterIfNeeded(request)
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	if len(services) != 1 || services[0].Port != 22 || services[0].Type != 28 {
+		t.Errorf("unexpected services %+v", services)
+		t.FailNow()
+	}
+
+	device, ok := server.Device(services[0].UID)
+	if !ok || device.Owner != USER || device.Secret != services[0].Secret {
+		t.Errorf("expected %s to be registered for %s, got %+v", services[0].UID, USER, device)
+	}
+
+	// registering again hands out the same service
+	again, errx := api.NewAutoRegistration(client).AutoRegisterIfNeeded(request)
+	if errx != nil || len(again) != 1 || again[0].UID != services[0].UID {
+		t.Errorf("expected %s again, got %+v %v", services[0].UID, again, errx)
+	}
+
+	request.RegistrationKey = "UNKNOWN"
+	if _, errx := api.NewAutoRegistration(client).AutoRegisterIfNeeded(request); !errors.Is(errx, apiContracts.ErrAutoreg_NoMatchingRegInfo) {
+		t.Errorf("expected %v, got %v", apiContracts.ErrAutoreg_NoMatchingRegInfo, errx)
+	}
+}
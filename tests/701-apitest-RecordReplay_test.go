@@ -0,0 +1,226 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/remoteit/sdk-go"
+	"github.com/remoteit/sdk-go/apitest"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+func Test_APITest_RecordReplay(t *testing.T) {
+	server := newFakeAPI()
+	golden := filepath.Join(t.TempDir(), "exchanges.json")
+
+	// 1. record
+	recorder := apitest.NewRecordingTransport(golden, nil)
+
+	client := api.NewClientWithTransport(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT, recorder)
+	recorded, errx := client.LoginWithPassword(USER, PASS)
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	graphQLClient := api.NewGraphQLClientWithTransport(server.GraphQLURL, recorded.Token, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT, recorder)
+	recordedServices, errx := graphQLClient.GetServiceNamesByIDs([]string{SERVICEID})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	restoreClient := api.NewRestoreClientWithTransport(server.RestoreURL, recorded.Token, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT, recorder)
+	if _, errx := restoreClient.Restore(DEVICEID, MACHINEID); errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	if err := recorder.Save(); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	server.Close()
+
+	data, _ := ioutil.ReadFile(golden)
+	device, _ := server.Device(SERVICEID)
+	for _, secret := range []string{recorded.Token, recorded.AuthHash, device.Secret} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("expected %s to be redacted from the golden file", secret)
+		}
+	}
+
+	// 2. replay, the server is gone
+	replay, err := apitest.NewReplayTransport(golden)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	client = api.NewClientWithTransport(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT, replay)
+	replayed, errx := client.LoginWithPassword(USER, PASS)
+	if errx != nil || replayed.UserID != recorded.UserID || replayed.Token != apitest.Redacted {
+		t.Errorf("unexpected replayed login %+v %v", replayed, errx)
+	}
+
+	graphQLClient = api.NewGraphQLClientWithTransport(server.GraphQLURL, replayed.Token, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT, replay)
+	replayedServices, errx := graphQLClient.GetServiceNamesByIDs([]string{SERVICEID})
+	if errx != nil || len(replayedServices) != 1 || replayedServices[0] != recordedServices[0] {
+		t.Errorf("expected %v, got %v %v", recordedServices, replayedServices, errx)
+	}
+
+	restoreClient = api.NewRestoreClientWithTransport(server.RestoreURL, replayed.Token, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT, replay)
+	config, errx := restoreClient.Restore(DEVICEID, MACHINEID)
+	if errx != nil || config.DeviceUID != DEVICEID || config.DeviceSecret != apitest.Redacted {
+		t.Errorf("unexpected replayed config %+v %v", config, errx)
+	}
+
+	if unused := replay.Unused(); len(unused) != 0 {
+		t.Errorf("expected every exchange to be replayed, left %v", unused)
+	}
+
+	// 3. anything not recorded fails, as does asking twice
+	if _, errx := client.LoginWithPassword("someone-else", PASS); !errors.Is(errx, apiContracts.ErrAPI_Auth_CantSendPasswordSignin) {
+		t.Errorf("expected an unrecorded request to fail, got %v", errx)
+	}
+	if _, errx := client.LoginWithPassword(USER, PASS); errx == nil {
+		t.Error("expected a replayed exchange not to be served twice")
+	}
+}
+
+func Test_APITest_RecordReplay_SecretPathsAndBinaryBodies(t *testing.T) {
+	// a gzip header is not valid UTF-8, the trailing whitespace is part of the checksum
+	bundle := append([]byte{0x1f, 0x8b, 0x08, 0x00, 0xff, 0xfe}, []byte("provisioning bundle \n\n")...)
+	hash := sha256.Sum256(bundle)
+	checksum := hex.EncodeToString(hash[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/device/address/"):
+			w.Write([]byte(`{"status":"true","deviceaddress":"` + SERVICEID + `"}`))
+		case strings.HasPrefix(r.URL.Path, "/project/provisioning/download/"):
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write(bundle)
+		case strings.HasPrefix(r.URL.Path, "/project/provisioning/"):
+			w.Write([]byte(`{"status":"true","project_id":"P1","version":"3","filename":"bundle.tar.gz","size":"28","sha256":"` + checksum + `"}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	golden := filepath.Join(t.TempDir(), "exchanges.json")
+
+	// 1. record
+	recorder := apitest.NewRecordingTransport(golden, nil)
+	client := api.NewClientWithTransport(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT, recorder)
+
+	if _, errx := api.NewService(client).GenerateUID("PROJECT-KEY", "PROJECT-SECRET"); errx != nil {
+		t.Fatal(errx)
+	}
+	if _, errx := api.NewAutoRegistration(client).GetProvisioning("REGISTRATION-KEY", "hardware-id"); errx != nil {
+		t.Fatal(errx)
+	}
+	if _, errx := api.NewAutoRegistration(client).DownloadProvisioning("REGISTRATION-KEY", "hardware-id", checksum, &bytes.Buffer{}); errx != nil {
+		t.Fatal(errx)
+	}
+
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	data, _ := ioutil.ReadFile(golden)
+	for _, secret := range []string{"PROJECT-KEY", "PROJECT-SECRET", "REGISTRATION-KEY"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("expected %s to be redacted from the golden file", secret)
+		}
+	}
+	if !strings.Contains(string(data), "/device/address/"+apitest.Redacted+"/"+apitest.Redacted) || !strings.Contains(string(data), "/project/provisioning/download/"+apitest.Redacted+"/hardware-id/") {
+		t.Errorf("expected the redacted paths in the golden file, got %s", data)
+	}
+
+	// 2. replay, the download still matches its checksum
+	replay, err := apitest.NewReplayTransport(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = api.NewClientWithTransport(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT, replay)
+
+	if uid, errx := api.NewService(client).GenerateUID("PROJECT-KEY", "PROJECT-SECRET"); errx != nil || uid != SERVICEID {
+		t.Errorf("unexpected replayed UID %s %v", uid, errx)
+	}
+	provisioning, errx := api.NewAutoRegistration(client).GetProvisioning("REGISTRATION-KEY", "hardware-id")
+	if errx != nil || provisioning.Checksum != checksum {
+		t.Errorf("unexpected replayed provisioning %+v %v", provisioning, errx)
+	}
+
+	var buffer bytes.Buffer
+	if _, errx := api.NewAutoRegistration(client).DownloadProvisioning("REGISTRATION-KEY", "hardware-id", checksum, &buffer); errx != nil || !bytes.Equal(buffer.Bytes(), bundle) {
+		t.Errorf("unexpected replayed download %q %v", buffer.Bytes(), errx)
+	}
+
+	if unused := replay.Unused(); len(unused) != 0 {
+		t.Errorf("expected every exchange to be replayed, left %v", unused)
+	}
+}
+
+func Test_APITest_RecordReplay_AutoRegistration(t *testing.T) {
+	server := newFakeAPI()
+	server.AddBulkProject(apitest.BulkProject{
+		RegistrationKey: "REGISTRATION-KEY",
+		ProjectID:       "P1",
+		Owner:           USER,
+		Templates:       []apitest.BulkTemplate{{ID: "T1", Type: 28, Hostname: "127.0.0.1", Port: "22"}},
+	})
+	golden := filepath.Join(t.TempDir(), "exchanges.json")
+
+	request := apiContracts.AutoRegistrationRequest{
+		RegistrationKey: "REGISTRATION-KEY",
+		HardwareID:      "hardware-id",
+		InitialBackoff:  time.Millisecond,
+	}
+
+	// 1. record
+	recorder := apitest.NewRecordingTransport(golden, nil)
+	client := api.NewClientWithTransport(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT, recorder)
+
+	recorded, errx := api.NewAutoRegistration(client).AutoRegisterIfNeeded(request)
+	if errx != nil || len(recorded) != 1 {
+		t.Fatalf("unexpected services %+v %v", recorded, errx)
+	}
+
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	data, _ := ioutil.ReadFile(golden)
+	for _, secret := range []string{"REGISTRATION-KEY", recorded[0].Secret} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("expected %s to be redacted from the golden file, got %s", secret, data)
+		}
+	}
+
+	// 2. replay
+	replay, err := apitest.NewReplayTransport(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = api.NewClientWithTransport(server.URL, APIKEY, apiContracts.DEFAULT_API_TIMEOUT, apiContracts.DEFAULT_API_USER_AGENT, replay)
+
+	replayed, errx := api.NewAutoRegistration(client).AutoRegisterIfNeeded(request)
+	if errx != nil || len(replayed) != 1 || replayed[0].UID != recorded[0].UID || replayed[0].Secret != apitest.Redacted {
+		t.Errorf("unexpected replayed services %+v %v", replayed, errx)
+	}
+	if unused := replay.Unused(); len(unused) != 0 {
+		t.Errorf("expected every exchange to be replayed, left %v", unused)
+	}
+}
@@ -0,0 +1,204 @@
+package tests
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	api "github.com/remoteit/sdk-go"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+)
+
+const profileTOML = `
+# staging first, production is the default
+defaultProfile = "production"
+
+[profiles.staging]
+apiURL = "https://staging.remote.it/apv/v27" # inline comment
+apiKey = "staging-key"
+timeout = 15
+
+[profiles.staging.rateLimits.device]
+requestsPerSecond = 2.5
+burst = 5
+
+[profiles."production"]
+apiKey = 'production#key'
+username = "user@remote.it"
+authHash = "hash"
+timeout = "1m30s"
+`
+
+const profileJSON = `{
+	"defaultProfile": "production",
+	"profiles": {
+		"staging": {"apiURL": "https://staging.remote.it/apv/v27", "apiKey": "staging-key", "timeout": 15, "rateLimits": {"device": {"requestsPerSecond": 2.5, "burst": 5}}},
+		"production": {"apiKey": "production#key", "username": "user@remote.it", "authHash": "hash", "timeout": "1m30s"}
+	}
+}`
+
+func Test_Config_LoadProfile(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, file := range []struct{ name, content string }{{"config.toml", profileTOML}, {"config.json", profileJSON}} {
+		path := filepath.Join(dir, file.name)
+		if err := ioutil.WriteFile(path, []byte(file.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		staging, errx := api.LoadProfile(path, "staging")
+		if errx != nil {
+			t.Fatalf("%s: %v", file.name, errx)
+		}
+		if staging.APIURL != "https://staging.remote.it/apv/v27" || staging.APIKey != "staging-key" || staging.Timeout != 15*time.Second {
+			t.Errorf("%s: unexpected staging profile %+v", file.name, staging)
+		}
+		if staging.GraphQLURL != apiContracts.DEFAULT_API_GRAPHQL_URL || staging.UserAgent != apiContracts.DEFAULT_API_USER_AGENT {
+			t.Errorf("%s: expected the defaults to fill in staging, got %+v", file.name, staging)
+		}
+		if limit := staging.RateLimits[apiContracts.RateLimitGroupDevice]; limit.RequestsPerSecond != 2.5 || limit.Burst != 5 {
+			t.Errorf("%s: unexpected staging rate limits %+v", file.name, staging.RateLimits)
+		}
+
+		production, errx := api.LoadProfile(path, "")
+		if errx != nil {
+			t.Fatalf("%s: %v", file.name, errx)
+		}
+		if production.Name != "production" || production.APIKey != "production#key" || production.APIURL != apiContracts.DEFAULT_API_URL ||
+			production.Timeout != 90*time.Second || !production.HasCredentials() {
+			t.Errorf("%s: unexpected default profile %+v", file.name, production)
+		}
+
+		if _, errx := api.LoadProfile(path, "missing"); !errors.Is(errx, apiContracts.ErrConfig_ProfileNotFound) {
+			t.Errorf("%s: expected a profile not found error, got %v", file.name, errx)
+		}
+	}
+
+	broken := filepath.Join(dir, "broken.toml")
+	if err := ioutil.WriteFile(broken, []byte("[profiles.staging]\napiKey = \"unterminated\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, errx := api.LoadProfileConfig(broken); !errors.Is(errx, apiContracts.ErrConfig_CantParse) {
+		t.Errorf("expected a parse error, got %v", errx)
+	}
+
+	unknownGroup := filepath.Join(dir, "unknown-group.json")
+	if err := ioutil.WriteFile(unknownGroup, []byte(`{"profiles": {"staging": {"rateLimits": {"devices": {"requestsPerSecond": 1}}}}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, errx := api.LoadProfileConfig(unknownGroup); !errors.Is(errx, apiContracts.ErrConfig_InvalidProfile) {
+		t.Errorf("expected an invalid profile error, got %v", errx)
+	}
+
+	if _, errx := api.LoadProfileConfig(filepath.Join(dir, "missing.json")); !errors.Is(errx, apiContracts.ErrConfig_CantRead) {
+		t.Errorf("expected a read error, got %v", errx)
+	}
+}
+
+func Test_Config_LoadProfile_TOMLStrings(t *testing.T) {
+	dir := t.TempDir()
+
+	// 1. an = inside a quoted key and the basic string escapes
+	path := filepath.Join(dir, "config.toml")
+	content := `profiles."key=value".apiKey = "tab\tquote\"backslash\\\u00e9\U0001F600"` + "\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, errx := api.LoadProfile(path, "key=value")
+	if errx != nil {
+		t.Fatal(errx)
+	}
+	if expected := "tab\tquote\"backslash\\\u00e9\U0001F600"; profile.APIKey != expected {
+		t.Errorf("expected %q, got %q", expected, profile.APIKey)
+	}
+
+	// 2. the escapes TOML doesn't have
+	for _, value := range []string{`"\x41"`, `"\101"`, `"\a"`, `"\u00e"`, `"\uD800"`, `"\U00110000"`, `"trailing\"`, `"a" "b"`} {
+		if err := ioutil.WriteFile(path, []byte("[profiles.staging]\napiKey = "+value+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, errx := api.LoadProfileConfig(path); !errors.Is(errx, apiContracts.ErrConfig_CantParse) {
+			t.Errorf("expected a parse error for %s, got %v", value, errx)
+		}
+	}
+}
+
+func Test_Config_LoadProfile_Environment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := ioutil.WriteFile(path, []byte(profileTOML), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	setenv(t, apiContracts.ENV_CONFIG, path)
+	setenv(t, apiContracts.ENV_PROFILE, "staging")
+	setenv(t, apiContracts.ENV_APIKEY, "env-key")
+	setenv(t, apiContracts.ENV_API_TIMEOUT, "5s")
+
+	profile, errx := api.LoadProfile("", "")
+	if errx != nil {
+		t.Fatal(errx)
+	}
+	if profile.Name != "staging" || profile.APIURL != "https://staging.remote.it/apv/v27" || profile.APIKey != "env-key" || profile.Timeout != 5*time.Second {
+		t.Errorf("expected the environment to override staging, got %+v", profile)
+	}
+
+	setenv(t, apiContracts.ENV_API_TIMEOUT, "soon")
+	if _, errx := api.LoadProfile("", ""); !errors.Is(errx, apiContracts.ErrConfig_InvalidProfile) {
+		t.Errorf("expected an invalid profile error, got %v", errx)
+	}
+}
+
+func Test_Config_LoadClients(t *testing.T) {
+	server := newFakeAPI()
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	config := `{"profiles": {"default": {
+		"apiURL": "` + server.URL + `",
+		"graphQLURL": "` + server.GraphQLURL + `",
+		"restoreURL": "` + server.RestoreURL + `",
+		"certificateURL": "` + server.CertificateURL + `",
+		"apiKey": "` + APIKEY + `",
+		"username": "` + USER + `",
+		"password": "` + PASS + `"
+	}}}`
+	if err := ioutil.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	clients, errx := api.LoadClients(path, "")
+	if errx != nil {
+		t.Fatal(errx)
+	}
+	if clients.Authentication.Token == "" {
+		t.Error("expected the clients to be logged in")
+	}
+
+	if list, errx := clients.Device.ListAll(); errx != nil || len(list.Devices) != 2 {
+		t.Errorf("expected 2 devices, got %v %v", list.Devices, errx)
+	}
+	if _, errx := clients.GraphQL.GetApplicationTypes(); errx != nil {
+		t.Error(errx)
+	}
+	if _, errx := clients.Restore.Restore(DEVICEID, MACHINEID); errx != nil {
+		t.Error(errx)
+	}
+}
+
+// setenv sets an environment variable for the rest of the test.
+func setenv(t *testing.T, name string, value string) {
+	previous, ok := os.LookupEnv(name)
+	os.Setenv(name, value)
+
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(name, previous)
+		} else {
+			os.Unsetenv(name)
+		}
+	})
+}
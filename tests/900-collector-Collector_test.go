@@ -0,0 +1,198 @@
+package tests
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/remoteit/sdk-go"
+	"github.com/remoteit/sdk-go/apitest"
+	"github.com/remoteit/sdk-go/collector"
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+func scrape(t *testing.T, metrics collector.Collector) map[string]float64 {
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %s", contentType)
+	}
+
+	body, _ := ioutil.ReadAll(recorder.Body)
+	samples := map[string]float64{}
+	for _, line := range strings.Split(strings.TrimSpace(string(body)), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		separator := strings.LastIndex(line, " ")
+		value, err := strconv.ParseFloat(line[separator+1:], 64)
+		if err != nil {
+			t.Fatalf("unexpected sample %q", line)
+		}
+		samples[line[:separator]] = value
+	}
+
+	return samples
+}
+
+func Test_Collector(t *testing.T) {
+	server := newFakeAPI()
+	defer server.Close()
+	server.AddDevice(apitest.Device{
+		Name:   "web",
+		Type:   apiContracts.GetServiceType(apiContracts.DefaultServiceType, 7, 0, 0),
+		Owner:  USER,
+		Active: true,
+	})
+
+	metrics := collector.New(apiContracts.CollectorOptions{})
+
+	sdk, errx := api.NewSDKWithInstrumentation(apiContracts.Profile{
+		APIURL:     server.URL,
+		GraphQLURL: server.GraphQLURL,
+		APIKey:     APIKEY,
+		Username:   USER,
+		Password:   PASS,
+	}, nil, api.Instrumentation{Metrics: metrics})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	metrics.Watch(sdk.Devices(), sdk.GraphQL())
+
+	// 1. the devices by state, application type and owner
+	if errx := metrics.Collect(); errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	samples := scrape(t, metrics)
+	for _, expected := range []string{
+		`remoteit_devices{owner="` + USER + `",state="inactive",type="Bulk Service"}`,
+		`remoteit_devices{owner="` + USER + `",state="inactive",type="SSH"}`,
+		`remoteit_devices{owner="` + USER + `",state="active",type="HTTP"}`,
+		`remoteit_collect_success`,
+	} {
+		if samples[expected] != 1 {
+			t.Errorf("expected %s to be 1, got %v", expected, samples)
+		}
+	}
+
+	// 2. the SDK metrics
+	if samples[`remoteit_api_request_duration_seconds_count{group="device",method="GET",status="200"}`] != 1 {
+		t.Errorf("expected the device list request, got %v", samples)
+	}
+	if samples[`remoteit_api_request_duration_seconds_bucket{group="device",method="GET",status="200",le="+Inf"}`] != 1 {
+		t.Errorf("expected the +Inf bucket, got %v", samples)
+	}
+
+	// 3. the tracked connections and their life left
+	proxies := metrics.TrackProxy(sdk.Proxies())
+	created, errx := proxies.Create(apiContracts.CreateProxyRequest{DeviceAddress: SERVICEID})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	samples = scrape(t, metrics)
+	if samples[`remoteit_proxy_connections{device="`+SERVICEID+`"}`] != 1 {
+		t.Errorf("expected 1 connection, got %v", samples)
+	}
+	lifeLeft := samples[`remoteit_proxy_connection_life_left_seconds{connection="`+created.Connection.ConnectionID+`",device="`+SERVICEID+`"}`]
+	if lifeLeft <= 0 || lifeLeft > apitest.DefaultConnectionLifetime.Seconds() {
+		t.Errorf("unexpected life left %v", lifeLeft)
+	}
+
+	if _, errx := proxies.Delete(apiContracts.DeleteProxyRequest{DeviceAddress: SERVICEID, ConnectionID: created.Connection.ConnectionID}); errx != nil {
+		t.Error(errx)
+	}
+	if samples = scrape(t, metrics); samples[`remoteit_proxy_connections{device="`+SERVICEID+`"}`] != 0 {
+		t.Errorf("expected the connection to be gone, got %v", samples)
+	}
+
+	// 4. the connections opened elsewhere come from the GraphQL device listing, without a life left
+	opened, errx := sdk.Proxies().Create(apiContracts.CreateProxyRequest{DeviceAddress: SERVICEID})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	if errx := metrics.Collect(); errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+
+	samples = scrape(t, metrics)
+	if samples[`remoteit_proxy_connections{device="`+SERVICEID+`"}`] != 1 {
+		t.Errorf("expected the listed connection, got %v", samples)
+	}
+	if _, ok := samples[`remoteit_proxy_connection_life_left_seconds{connection="`+opened.Connection.ConnectionID+`",device="`+SERVICEID+`"}`]; ok {
+		t.Errorf("expected no life left for a listed connection, got %v", samples)
+	}
+
+	// 5. a failed listing keeps the last counts
+	server.Fail("/device/list/all", apitest.Failure{Reason: "[0999] maintenance", Times: 1})
+	if errx := metrics.Collect(); errx == nil {
+		t.Error("expected an error")
+	}
+
+	samples = scrape(t, metrics)
+	if samples[`remoteit_collect_success`] != 0 || samples[`remoteit_devices{owner="`+USER+`",state="inactive",type="SSH"}`] != 1 {
+		t.Errorf("expected a failed collect with the last counts, got %v", samples)
+	}
+	counted := 0.0
+	for key, value := range samples {
+		if strings.HasPrefix(key, "remoteit_api_errors_total{") && strings.Contains(key, `operation="Device.ListAll"`) {
+			counted += value
+		}
+	}
+	if counted != 1 {
+		t.Errorf("expected the error to be counted, got %v", samples)
+	}
+}
+
+func Test_Collector_StartStop(t *testing.T) {
+	server := newFakeAPI()
+	defer server.Close()
+
+	errors := make(chan errorx.Error, 10)
+	metrics := collector.New(apiContracts.CollectorOptions{
+		Interval: 10 * time.Millisecond,
+		OnError:  func(errx errorx.Error) { errors <- errx },
+	})
+
+	sdk, errx := api.NewSDK(apiContracts.Profile{APIURL: server.URL, APIKey: APIKEY, Username: USER, Password: PASS})
+	if errx != nil {
+		t.Error(errx)
+		t.FailNow()
+	}
+	metrics.Watch(sdk.Devices(), nil)
+
+	server.Fail("/device/list/all", apitest.Failure{Reason: "[0999] maintenance", Times: 1})
+	metrics.Start()
+	defer metrics.Stop()
+
+	select {
+	case <-errors:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the first collect to fail")
+	}
+
+	// the next collect succeeds, without GraphQL the application type IDs are exported
+	deadline := time.Now().Add(5 * time.Second)
+	for scrape(t, metrics)[`remoteit_collect_success`] != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected a successful collect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if samples := scrape(t, metrics); samples[`remoteit_devices{owner="`+USER+`",state="inactive",type="28"}`] != 1 {
+		t.Errorf("expected the SSH service by ID, got %v", samples)
+	}
+}
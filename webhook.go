@@ -0,0 +1,303 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	apiContracts "github.com/remoteit/sdk-go/contracts"
+	errorx "github.com/remoteit/systemkit-errorx"
+)
+
+type DeviceStateHandler func(event apiContracts.DeviceStateEvent) errorx.Error
+type ConnectionHandler func(event apiContracts.ConnectionEvent) errorx.Error
+type WebhookHandler func(event apiContracts.WebhookEvent) errorx.Error
+
+// WebhookReceiver is the `http.Handler` of the remote.it notification webhooks. It verifies
+// every notification and its timestamp, drops the ones already handled within `DedupeWindow`
+// and calls the handlers of its type in the order they were added. A handler error replies
+// 500 so the notification is sent again, and handled again.
+type WebhookReceiver interface {
+	http.Handler
+
+	OnDeviceState(handler DeviceStateHandler)
+	OnConnection(handler ConnectionHandler)
+	OnEvent(handler WebhookHandler) // every notification, including the types the SDK does not know
+}
+
+func NewWebhookReceiver(options apiContracts.WebhookReceiverOptions) WebhookReceiver {
+	if options.TimestampTolerance <= 0 {
+		options.TimestampTolerance = apiContracts.DEFAULT_WEBHOOK_TIMESTAMP_TOLERANCE
+	}
+	if options.DedupeWindow <= 0 {
+		options.DedupeWindow = apiContracts.DEFAULT_WEBHOOK_DEDUPE_WINDOW
+	}
+	if options.DedupeWindow < 2*options.TimestampTolerance {
+		options.DedupeWindow = 2 * options.TimestampTolerance
+	}
+	if options.MaxBodySize <= 0 {
+		options.MaxBodySize = apiContracts.DEFAULT_WEBHOOK_MAX_BODY_SIZE
+	}
+
+	return &webhookReceiver{
+		options: options,
+		seen:    map[string]time.Time{},
+	}
+}
+
+type seenEvent struct {
+	key string
+	at  time.Time
+}
+
+type webhookReceiver struct {
+	options apiContracts.WebhookReceiverOptions
+
+	handlersMutex       sync.RWMutex
+	deviceStateHandlers []DeviceStateHandler
+	connectionHandlers  []ConnectionHandler
+	eventHandlers       []WebhookHandler
+
+	seenMutex sync.Mutex
+	seen      map[string]time.Time // by dedupeKey
+	seenOrder []seenEvent          // oldest first, to forget them once out of the window
+}
+
+func (thisRef *webhookReceiver) OnDeviceState(handler DeviceStateHandler) {
+	thisRef.handlersMutex.Lock()
+	defer thisRef.handlersMutex.Unlock()
+
+	thisRef.deviceStateHandlers = append(thisRef.deviceStateHandlers, handler)
+}
+
+func (thisRef *webhookReceiver) OnConnection(handler ConnectionHandler) {
+	thisRef.handlersMutex.Lock()
+	defer thisRef.handlersMutex.Unlock()
+
+	thisRef.connectionHandlers = append(thisRef.connectionHandlers, handler)
+}
+
+func (thisRef *webhookReceiver) OnEvent(handler WebhookHandler) {
+	thisRef.handlersMutex.Lock()
+	defer thisRef.handlersMutex.Unlock()
+
+	thisRef.eventHandlers = append(thisRef.eventHandlers, handler)
+}
+
+func (thisRef *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 1. read and verify
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, thisRef.options.MaxBodySize))
+	if err != nil {
+		thisRef.reply(w, http.StatusBadRequest, apiContracts.NewErrorFromErr(apiContracts.ErrWebhook_CantReadBody.Code(), err))
+		return
+	}
+
+	if errx := thisRef.verify(r, body); errx != nil {
+		thisRef.reply(w, http.StatusUnauthorized, errx)
+		return
+	}
+
+	// 2. decode, before the dedupe since a malformed notification stays malformed when sent again
+	notification, errx := decodeWebhook(body)
+	if errx != nil {
+		thisRef.reply(w, http.StatusBadRequest, errx)
+		return
+	}
+
+	// 3. refuse the replays, the signature covers the timestamp
+	if !thisRef.isRecent(notification.event.Timestamp) {
+		thisRef.reply(w, http.StatusUnauthorized, apiContracts.ErrWebhook_StaleTimestamp)
+		return
+	}
+
+	// 4. dedupe
+	key := dedupeKey(notification.event.ID, body)
+	if !thisRef.markSeen(key) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 5. dispatch
+	if errx := thisRef.dispatch(notification); errx != nil {
+		thisRef.forget(key)
+
+		thisRef.reply(w, http.StatusInternalServerError, errx)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// verify accepts a valid WEBHOOK_SIGNATURE_HEADER, or WEBHOOK_SECRET_HEADER when
+// AllowSecretHeader is set. Without a secret only an Insecure receiver accepts anything.
+func (thisRef *webhookReceiver) verify(r *http.Request, body []byte) errorx.Error {
+	if thisRef.options.Secret == "" {
+		if thisRef.options.Insecure {
+			return nil
+		}
+		return apiContracts.ErrWebhook_NoSecret
+	}
+
+	if signature := r.Header.Get(apiContracts.WEBHOOK_SIGNATURE_HEADER); signature != "" {
+		expected := apiContracts.WebhookSignature(thisRef.options.Secret, body)
+		if subtle.ConstantTimeCompare([]byte(strings.ToLower(signature)), []byte(expected)) == 1 {
+			return nil
+		}
+		return apiContracts.ErrWebhook_InvalidSignature
+	}
+
+	if secret := r.Header.Get(apiContracts.WEBHOOK_SECRET_HEADER); secret != "" && thisRef.options.AllowSecretHeader {
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(thisRef.options.Secret)) == 1 {
+			return nil
+		}
+	}
+
+	return apiContracts.ErrWebhook_InvalidSignature
+}
+
+// isRecent tells if `timestamp` is within the tolerance of now, either way.
+func (thisRef *webhookReceiver) isRecent(timestamp time.Time) bool {
+	if timestamp.IsZero() {
+		return false
+	}
+
+	age := time.Since(timestamp)
+	if age < 0 {
+		age = -age
+	}
+
+	return age <= thisRef.options.TimestampTolerance
+}
+
+// webhookNotification is the event with its typed data, if its type is known.
+type webhookNotification struct {
+	event       apiContracts.WebhookEvent
+	deviceState *apiContracts.DeviceStateEvent
+	connection  *apiContracts.ConnectionEvent
+}
+
+func decodeWebhook(body []byte) (webhookNotification, errorx.Error) {
+	notification := webhookNotification{}
+	if err := json.Unmarshal(body, &notification.event); err != nil {
+		return webhookNotification{}, apiContracts.NewErrorFromErr(apiContracts.ErrWebhook_CantDecode.Code(), err)
+	}
+
+	event := notification.event
+
+	var data interface{}
+	switch event.Type {
+	case apiContracts.WebhookDeviceState:
+		notification.deviceState = &apiContracts.DeviceStateEvent{WebhookEvent: event}
+		data = notification.deviceState
+
+	case apiContracts.WebhookDeviceConnect, apiContracts.WebhookDeviceDisconnect:
+		notification.connection = &apiContracts.ConnectionEvent{
+			WebhookEvent: event,
+			Connected:    event.Type == apiContracts.WebhookDeviceConnect,
+		}
+		data = notification.connection
+	}
+
+	if data != nil && len(event.Data) > 0 {
+		if err := json.Unmarshal(event.Data, data); err != nil {
+			return webhookNotification{}, apiContracts.NewErrorFromErr(apiContracts.ErrWebhook_CantDecode.Code(), err)
+		}
+	}
+
+	return notification, nil
+}
+
+func (thisRef *webhookReceiver) dispatch(notification webhookNotification) errorx.Error {
+	thisRef.handlersMutex.RLock()
+	deviceStateHandlers := thisRef.deviceStateHandlers
+	connectionHandlers := thisRef.connectionHandlers
+	eventHandlers := thisRef.eventHandlers
+	thisRef.handlersMutex.RUnlock()
+
+	if notification.deviceState != nil {
+		for _, handler := range deviceStateHandlers {
+			if errx := handler(*notification.deviceState); errx != nil {
+				return errx
+			}
+		}
+	}
+
+	if notification.connection != nil {
+		for _, handler := range connectionHandlers {
+			if errx := handler(*notification.connection); errx != nil {
+				return errx
+			}
+		}
+	}
+
+	for _, handler := range eventHandlers {
+		if errx := handler(notification.event); errx != nil {
+			return errx
+		}
+	}
+
+	return nil
+}
+
+// dedupeKey is the ID of a notification, or the hash of its body without one so that the same
+// notification sent again is still dropped.
+func dedupeKey(id string, body []byte) string {
+	if id != "" {
+		return "id:" + id
+	}
+
+	hash := sha256.Sum256(body)
+	return "body:" + hex.EncodeToString(hash[:])
+}
+
+// markSeen returns false when `key` was already handled within the window.
+func (thisRef *webhookReceiver) markSeen(key string) bool {
+	thisRef.seenMutex.Lock()
+	defer thisRef.seenMutex.Unlock()
+
+	now := time.Now()
+	for len(thisRef.seenOrder) > 0 && now.Sub(thisRef.seenOrder[0].at) >= thisRef.options.DedupeWindow {
+		oldest := thisRef.seenOrder[0]
+		if at, ok := thisRef.seen[oldest.key]; ok && at.Equal(oldest.at) {
+			delete(thisRef.seen, oldest.key)
+		}
+		thisRef.seenOrder = thisRef.seenOrder[1:]
+	}
+
+	if _, ok := thisRef.seen[key]; ok {
+		return false
+	}
+
+	thisRef.seen[key] = now
+	thisRef.seenOrder = append(thisRef.seenOrder, seenEvent{key: key, at: now})
+
+	return true
+}
+
+// forget lets a notification that failed be handled when it is sent again.
+func (thisRef *webhookReceiver) forget(key string) {
+	thisRef.seenMutex.Lock()
+	defer thisRef.seenMutex.Unlock()
+
+	delete(thisRef.seen, key)
+}
+
+func (thisRef *webhookReceiver) reply(w http.ResponseWriter, statusCode int, errx errorx.Error) {
+	if thisRef.options.OnError != nil {
+		thisRef.options.OnError(errx)
+	}
+
+	http.Error(w, errx.Message(), statusCode)
+}